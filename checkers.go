@@ -1,5 +1,23 @@
 package paco
 
+// rawByteBase maps raw bytes to the low surrogates U+DC80 to U+DCFF, which are never the result of decoding
+// valid UTF-8
+const rawByteBase = 0xDC00
+
+// RawByte returns the byte a rune represents when parsing with InvalidUTF8Raw
+func RawByte(r rune) (byte, bool) {
+	if r < rawByteBase+0x80 || r > rawByteBase+0xFF {
+		return 0, false
+	}
+	return byte(r - rawByteBase), true
+}
+
+// IsRawByte returns true if the given rune represents an invalid byte when parsing with InvalidUTF8Raw
+func IsRawByte(r rune) bool {
+	_, ok := RawByte(r)
+	return ok
+}
+
 // MatchAny returns a predicate that tests the given predicates in order. Returns true if any predicate matches.
 func MatchAny(c ...func(rune) bool) func(rune) bool {
 	return func(r rune) bool {
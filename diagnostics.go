@@ -0,0 +1,201 @@
+package paco
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"path/filepath"
+)
+
+// Severity of a Diagnostic. The values match the SARIF result levels.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+const (
+	defaultErrorRule   = "parse-error"
+	defaultWarningRule = "parse-warning"
+)
+
+// Diagnostic is a machine-readable description of a parse error or warning. It can be serialized as JSON directly
+// or written as SARIF with WriteSARIF.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	// Rule identifies the kind of diagnostic. For errors, it's the innermost label.
+	Rule      string   `json:"rule"`
+	Message   string   `json:"message"`
+	Filename  string   `json:"filename,omitempty"`
+	Offset    int      `json:"offset"`
+	Line      int      `json:"line"`
+	Column    int      `json:"column"`
+	EndLine   int      `json:"endLine"`
+	EndColumn int      `json:"endColumn"`
+	Expected  []string `json:"expected,omitempty"`
+	Labels    []string `json:"labels,omitempty"`
+}
+
+// Diagnostics converts the error and warnings returned by Parse or ParseWithWarnings to diagnostics.
+// err may be nil, a *ParseError or an ErrorList.
+func Diagnostics(err error, warnings []Warning) []Diagnostic {
+	var diagnostics []Diagnostic
+	var list ErrorList
+	var pe *ParseError
+	if errors.As(err, &list) {
+		for _, e := range list {
+			diagnostics = append(diagnostics, errorDiagnostic(e))
+		}
+	} else if errors.As(err, &pe) {
+		diagnostics = append(diagnostics, errorDiagnostic(pe))
+	}
+	for _, w := range warnings {
+		diagnostics = append(diagnostics, Diagnostic{
+			Severity:  SeverityWarning,
+			Rule:      defaultWarningRule,
+			Message:   w.Message,
+			Filename:  w.Filename,
+			Offset:    w.Offset,
+			Line:      w.Line,
+			Column:    w.Column,
+			EndLine:   w.Line,
+			EndColumn: w.Column + 1,
+		})
+	}
+	return diagnostics
+}
+
+func errorDiagnostic(e *ParseError) Diagnostic {
+	rule := defaultErrorRule
+	if labels := e.labels(LabelsInnermost); len(labels) > 0 {
+		rule = labels[0]
+	}
+	message := e.message()
+	if e.Furthest != nil {
+		message += " (furthest failure: " + e.Furthest.Message(LabelsAll) + ")"
+	}
+	return Diagnostic{
+		Severity:  SeverityError,
+		Rule:      rule,
+		Message:   message,
+		Filename:  e.Filename,
+		Offset:    e.Offset,
+		Line:      e.Line,
+		Column:    e.Column,
+		EndLine:   e.Line,
+		EndColumn: e.Column + 1,
+		Expected:  e.Expected,
+		Labels:    e.labels(LabelsAll),
+	}
+}
+
+// WriteSARIF writes the diagnostics as a SARIF 2.1.0 log with a single run of the named tool. uri identifies the
+// parsed file for diagnostics without a Filename, filenames are converted to relative URI references. Columns are given in Unicode code points, as computed with the
+// default tab width of 1.
+func WriteSARIF(w io.Writer, tool string, uri string, diagnostics []Diagnostic) error {
+	rules := make([]sarifRule, 0)
+	results := make([]sarifResult, 0, len(diagnostics))
+	seen := make(map[string]bool)
+	for _, d := range diagnostics {
+		if !seen[d.Rule] {
+			seen[d.Rule] = true
+			rules = append(rules, sarifRule{ID: d.Rule})
+		}
+		artifact := uri
+		if d.Filename != "" {
+			artifact = filenameURI(d.Filename)
+		}
+		results = append(results, sarifResult{
+			RuleID:  d.Rule,
+			Level:   string(d.Severity),
+			Message: sarifMessage{Text: d.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: artifact},
+					Region: sarifRegion{
+						StartLine:   d.Line,
+						StartColumn: d.Column,
+						EndLine:     d.EndLine,
+						EndColumn:   d.EndColumn,
+					},
+				},
+			}},
+		})
+	}
+	log := sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool:       sarifTool{Driver: sarifDriver{Name: tool, Rules: rules}},
+			ColumnKind: "unicodeCodePoints",
+			Results:    results,
+		}},
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+// filenameURI converts a filename to a URI reference, escaping spaces, percent signs and the like
+func filenameURI(name string) string {
+	u := url.URL{Path: filepath.ToSlash(name)}
+	return u.String()
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
@@ -0,0 +1,95 @@
+package paco
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestDiagnostics(t *testing.T) {
+	entry := WithLabel(Recover(WithWarning(Exactly("a"), "deprecated"), Exactly(","), empty), "entry")
+	parser := SepBy(entry, Exactly(","))
+
+	_, warnings, err := ParseWithWarnings(parser, "a,b")
+	diagnostics := Diagnostics(err, warnings)
+	if len(diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %v", diagnostics)
+	}
+
+	e := diagnostics[0]
+	if e.Severity != SeverityError || e.Offset != 2 || e.Line != 1 || e.Column != 3 {
+		t.Errorf("unexpected error diagnostic %+v", e)
+	}
+	if e.Rule != "entry" || len(e.Labels) != 1 {
+		t.Errorf("expected rule 'entry', got '%s' with labels %v", e.Rule, e.Labels)
+	}
+	if e.Message != `expected "a"` {
+		t.Errorf("expected message 'expected \"a\"', got '%s'", e.Message)
+	}
+
+	w := diagnostics[1]
+	if w.Severity != SeverityWarning || w.Rule != "parse-warning" || w.Message != "deprecated" || w.Offset != 0 {
+		t.Errorf("unexpected warning diagnostic %+v", w)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	parser := WithLabel(Exactly("a"), "letter")
+	_, err := Parse(parser, "b")
+
+	buf := bytes.Buffer{}
+	if err := WriteSARIF(&buf, "linter", "file:///input.txt", Diagnostics(err, nil)); err != nil {
+		t.Fatalf("unable to write SARIF: %v", err)
+	}
+
+	var log struct {
+		Version string
+		Runs    []struct {
+			ColumnKind string
+			Results    []struct {
+				RuleID    string
+				Level     string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           struct{ StartLine, StartColumn, EndLine, EndColumn int }
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("unable to read SARIF: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 1 {
+		t.Fatalf("unexpected SARIF log %s", buf.String())
+	}
+	if log.Runs[0].ColumnKind != "unicodeCodePoints" {
+		t.Errorf("expected column kind 'unicodeCodePoints', got '%s'", log.Runs[0].ColumnKind)
+	}
+	result := log.Runs[0].Results[0]
+	if result.RuleID != "letter" || result.Level != "error" {
+		t.Errorf("expected error with rule 'letter', got %s %s", result.Level, result.RuleID)
+	}
+	location := result.Locations[0].PhysicalLocation
+	if location.ArtifactLocation.URI != "file:///input.txt" {
+		t.Errorf("expected uri 'file:///input.txt', got '%s'", location.ArtifactLocation.URI)
+	}
+	if location.Region.StartLine != 1 || location.Region.StartColumn != 1 || location.Region.EndColumn != 2 {
+		t.Errorf("unexpected region %+v", location.Region)
+	}
+}
+
+func TestWriteSARIF_filename(t *testing.T) {
+	fset := NewFileSet()
+	file := fset.AddFile("my dir/50%.conf", "b")
+	_, err := ParseFile(Exactly("a"), file)
+
+	buf := bytes.Buffer{}
+	if err := WriteSARIF(&buf, "linter", "file:///input.txt", Diagnostics(err, nil)); err != nil {
+		t.Fatalf("unable to write SARIF: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"uri": "my%20dir/50%25.conf"`)) {
+		t.Errorf("expected escaped uri, got %s", buf.String())
+	}
+}
@@ -0,0 +1,64 @@
+package paco
+
+import (
+	"errors"
+	"io"
+)
+
+// ParseEachFunc reads records from r by repeatedly applying parser until the input ends and calls fn with each
+// record as soon as it has been parsed. Input is released after every record, so memory usage is bounded by the
+// size of a single record. It stops at the first error, which is either returned by fn or a parse error. Like
+// ParseEach, records the parser recovered from errors in (see Recover) are passed to fn and iteration continues.
+// The recovered errors are returned as ErrorList at the end, followed by the parse error if parsing failed.
+func ParseEachFunc[T any](parser Parser[T], r io.Reader, fn func(T) error, opts ...Option) error {
+	var recovered ErrorList
+	var fnErr error
+	err := each(parser, r, opts, func(t T, errs ErrorList) bool {
+		recovered = append(recovered, errs...)
+		fnErr = fn(t)
+		return fnErr == nil
+	})
+	switch {
+	case fnErr != nil:
+		return fnErr
+	case len(recovered) == 0:
+		return err
+	case err == nil:
+		return recovered
+	}
+	var errs ErrorList
+	if errors.As(err, &errs) {
+		return append(recovered, errs...)
+	}
+	return append(recovered, asParseError(State{}, err))
+}
+
+// each applies parser to r until the input ends and yields every record together with the errors recovered from
+// while parsing it. It returns the error parsing failed with. Iteration ends early if yield returns false.
+func each[T any](parser Parser[T], r io.Reader, opts []Option, yield func(T, ErrorList) bool) error {
+	current := readerState(r, opts)
+	for current.HasRemaining() {
+		t, next, err := parser(current)
+		if inputErr := current.inputError(); inputErr != nil {
+			return current.env.finishError(newError(next, inputErr))
+		}
+		if err != nil {
+			return current.env.finishFailure(asParseError(next, err))
+		}
+		if next.Offset == current.Offset {
+			return current.env.finishFailure(unconsumedInput(next))
+		}
+		current.env.collectWarnings(next)
+		if !yield(t, next.errors()) {
+			return nil
+		}
+		current = next
+		current.warnings = nil
+		current.diagnostics = nil
+		current.failure = nil
+	}
+	if inputErr := current.inputError(); inputErr != nil {
+		return current.env.finishError(newError(current, inputErr))
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+//go:build go1.23
+
+package paco
+
+import (
+	"io"
+	"iter"
+)
+
+// ParseEach reads records from r by repeatedly applying parser until the input ends and yields each record as
+// soon as it has been parsed. Input is released after every record, so memory usage is bounded by the size of a
+// single record. Iteration ends after the first parse error. Records the parser recovered from errors in (see
+// Recover) are yielded together with an ErrorList. Use ParseEachFunc on Go versions before 1.23.
+func ParseEach[T any](parser Parser[T], r io.Reader, opts ...Option) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		err := each(parser, r, opts, func(t T, errs ErrorList) bool {
+			if len(errs) > 0 {
+				return yield(t, errs)
+			}
+			return yield(t, nil)
+		})
+		if err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
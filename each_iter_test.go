@@ -0,0 +1,26 @@
+//go:build go1.23
+
+package paco
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEach(t *testing.T) {
+	record := AppendSkipping(GetString(ConsumeSome(IsAsciiLetter)), Exactly("\n"))
+
+	var records []string
+	for r, err := range ParseEach(record, strings.NewReader("a\nbc\ndef\n")) {
+		if err != nil {
+			t.Fatalf("parser didn't parse: %v", err)
+		}
+		records = append(records, r)
+		if len(records) == 2 {
+			break
+		}
+	}
+	if strings.Join(records, " ") != "a bc" {
+		t.Errorf("expected [a bc], got %v", records)
+	}
+}
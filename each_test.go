@@ -0,0 +1,74 @@
+package paco
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseEachFunc(t *testing.T) {
+	record := AppendSkipping(GetString(ConsumeSome(IsAsciiLetter)), Exactly("\n"))
+
+	var records []string
+	err := ParseEachFunc(record, strings.NewReader("a\nbc\ndef\n"), func(r string) error {
+		records = append(records, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if strings.Join(records, " ") != "a bc def" {
+		t.Errorf("expected [a bc def], got %v", records)
+	}
+
+	records = nil
+	err = ParseEachFunc(record, strings.NewReader("a\nb1\nc\n"), func(r string) error {
+		records = append(records, r)
+		return nil
+	})
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected ParseError, got %v", err)
+	}
+	if pe.Line != 2 || pe.Column != 2 {
+		t.Errorf("expected error at 2:2, got %d:%d", pe.Line, pe.Column)
+	}
+	if len(records) != 1 {
+		t.Errorf("expected records before the error to be reported, got %v", records)
+	}
+}
+
+func TestParseEachFunc_callback_error(t *testing.T) {
+	stop := errors.New("stop")
+	calls := 0
+	err := ParseEachFunc(Exactly("a"), strings.NewReader("aaa"), func(Empty) error {
+		calls++
+		return stop
+	})
+	if err != stop {
+		t.Errorf("expected callback error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestParseEachFunc_recovered(t *testing.T) {
+	record := AppendSkipping(Recover(GetString(ConsumeSome(IsAsciiLetter)), Exactly("\n"), "?"), Exactly("\n"))
+
+	var records []string
+	err := ParseEachFunc(record, strings.NewReader("a\n1\nc\n"), func(r string) error {
+		records = append(records, r)
+		return nil
+	})
+	var errs ErrorList
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("expected ErrorList with 1 error, got %v", err)
+	}
+	if errs[0].Line != 2 {
+		t.Errorf("expected error in line 2, got %v", errs[0])
+	}
+	if strings.Join(records, " ") != "a ? c" {
+		t.Errorf("expected [a ? c], got %v", records)
+	}
+}
@@ -0,0 +1,269 @@
+package paco
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseError describes a parsing failure at a specific position of the input.
+// It wraps the underlying cause, so errors.Is(err, ErrNoMatch) keeps working.
+type ParseError struct {
+	// Filename is the name of the file in which parsing failed when parsing a File of a FileSet
+	Filename string
+	// Pos is the position within the FileSet at which parsing failed, NoPos if not parsing a File
+	Pos Pos
+	// Offset is the byte offset at which parsing failed
+	Offset int
+	// Line is the 1-based line at which parsing failed
+	Line int
+	// Column is the 1-based column at which parsing failed
+	Column int
+	// Labels contains the frames of all enclosing WithLabel parsers, outermost first
+	Labels []LabelFrame
+	// Expected contains descriptions of the inputs that would have been accepted at Offset
+	Expected []string
+	// Fatal is set on errors that must not be recovered from by trying alternatives. See Cut.
+	Fatal bool
+	// Furthest is the furthest failure that stopped the parser from consuming more input. Only set on errors
+	// wrapping ErrUnconsumedInput.
+	Furthest *ParseError
+	// Err is the underlying cause
+	Err error
+	// labelMode selects the labels rendered by Error, see WithLabelMode
+	labelMode LabelMode
+	// diagnostics contains the errors recovered from on the way to this error, see Recover
+	diagnostics *stack[*ParseError]
+}
+
+// LabelFrame records a WithLabel parser that was active when an error occurred
+type LabelFrame struct {
+	// Label is the label given to WithLabel
+	Label string
+	// Offset is the byte offset at which the labeled parser started
+	Offset int
+}
+
+// LabelMode selects which labels are rendered in error messages
+type LabelMode int
+
+const (
+	// LabelsAll renders the whole label chain
+	LabelsAll LabelMode = iota
+	// LabelsInnermost renders only the innermost label
+	LabelsInnermost
+	// LabelsOutermost renders only the outermost label
+	LabelsOutermost
+	// LabelsNone renders no labels
+	LabelsNone
+)
+
+// newError creates a ParseError for the given cause at the position of the given state.
+func newError(s State, err error) *ParseError {
+	pos := s.Position()
+	return &ParseError{
+		Filename:    pos.Filename,
+		Pos:         s.Pos(),
+		Offset:      pos.Offset,
+		Line:        pos.Line,
+		Column:      pos.Column,
+		Err:         err,
+		diagnostics: s.diagnostics,
+	}
+}
+
+// asParseError returns err as a ParseError. Errors of other types are wrapped at the position of the given state.
+func asParseError(s State, err error) *ParseError {
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		return pe
+	}
+	return newError(s, err)
+}
+
+func (e *ParseError) Error() string {
+	return e.Message(e.labelMode)
+}
+
+// Message renders the error with the labels selected by mode
+func (e *ParseError) Message(mode LabelMode) string {
+	b := strings.Builder{}
+	for _, label := range e.labels(mode) {
+		b.WriteString("error parsing ")
+		b.WriteString(label)
+		b.WriteString(": ")
+	}
+	b.WriteString(e.message())
+	b.WriteString(" at ")
+	b.WriteString(e.position())
+	if e.Furthest != nil {
+		b.WriteString(" (furthest failure: ")
+		b.WriteString(e.Furthest.Message(mode))
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+// message describes the error without labels and position. Causes other than ErrNoMatch, e.g. from Validate, are
+// preferred over the expected set.
+func (e *ParseError) message() string {
+	if len(e.Expected) > 0 && e.isNoMatch() {
+		return "expected " + joinExpected(e.Expected)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying cause
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// isNoMatch returns true if the input just didn't match, as opposed to a semantic error like one from Validate
+func (e *ParseError) isNoMatch() bool {
+	return errors.Is(e.Err, ErrNoMatch)
+}
+
+// labels returns the label names selected by mode, outermost first
+func (e *ParseError) labels(mode LabelMode) []string {
+	frames := e.Labels
+	switch {
+	case len(frames) == 0 || mode == LabelsNone:
+		return nil
+	case mode == LabelsInnermost:
+		frames = frames[len(frames)-1:]
+	case mode == LabelsOutermost:
+		frames = frames[:1]
+	}
+	names := make([]string, len(frames))
+	for i, f := range frames {
+		names[i] = f.Label
+	}
+	return names
+}
+
+// withLabel returns a copy of the error with the given frame as the new outermost label
+func (e *ParseError) withLabel(frame LabelFrame) *ParseError {
+	labeled := *e
+	labeled.Labels = append([]LabelFrame{frame}, e.Labels...)
+	return &labeled
+}
+
+// IsFatal returns true if err is a fatal ParseError
+func IsFatal(err error) bool {
+	var pe *ParseError
+	return errors.As(err, &pe) && pe.Fatal
+}
+
+// fatal returns a fatal copy of the error
+func (e *ParseError) fatal() *ParseError {
+	if e.Fatal {
+		return e
+	}
+	f := *e
+	f.Fatal = true
+	return &f
+}
+
+// withExpected returns a copy of the error with the expected set replaced by the given one
+func (e *ParseError) withExpected(expected ...string) *ParseError {
+	replaced := *e
+	replaced.Expected = expected
+	return &replaced
+}
+
+// furthest returns the error that got further into the input. If both errors failed at the same offset, a copy
+// with the merged expected sets and the label frames both share is returned, unless one of them has a cause other than ErrNoMatch. That one is
+// returned unchanged then.
+func furthest(a, b *ParseError) *ParseError {
+	if a == nil || b.Offset > a.Offset {
+		return b
+	}
+	if b.Offset < a.Offset || !a.isNoMatch() {
+		return a
+	}
+	if !b.isNoMatch() {
+		return b
+	}
+	merged := a.Expected
+	for _, e := range b.Expected {
+		if !contains(merged, e) {
+			merged = append(merged[:len(merged):len(merged)], e)
+		}
+	}
+	result := a.withExpected(merged...)
+	result.Labels = commonLabels(a.Labels, b.Labels)
+	return result
+}
+
+// commonLabels returns the outermost frames both label stacks share. Frames added inside only one of the merged
+// alternatives don't describe the merged failure.
+func commonLabels(a, b []LabelFrame) []LabelFrame {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	return a[:n:n]
+}
+
+// joinExpected renders the expected set as 'a, b or c'
+func joinExpected(expected []string) string {
+	if len(expected) == 1 {
+		return expected[0]
+	}
+	return strings.Join(expected[:len(expected)-1], ", ") + " or " + expected[len(expected)-1]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *ParseError) position() string {
+	return Position{Filename: e.Filename, Offset: e.Offset, Line: e.Line, Column: e.Column}.String()
+}
+
+// ErrorList is a list of errors returned by Parse when errors were recovered from. See Recover.
+type ErrorList []*ParseError
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0].Error(), len(l)-1)
+}
+
+// Unwrap returns all errors of the list
+func (l ErrorList) Unwrap() []error {
+	errs := make([]error, len(l))
+	for i, e := range l {
+		errs[i] = e
+	}
+	return errs
+}
+
+// unexpectedError describes input that matched although it must not. It wraps ErrNoMatch.
+type unexpectedError struct {
+	text string
+}
+
+func (e unexpectedError) Error() string {
+	if e.text == "" {
+		return "unexpected input"
+	}
+	return "unexpected " + strconv.Quote(e.text)
+}
+
+func (e unexpectedError) Unwrap() error {
+	return ErrNoMatch
+}
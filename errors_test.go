@@ -0,0 +1,173 @@
+package paco
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseError_position(t *testing.T) {
+	parser := AppendSkipping(StartSkipping(Exactly("ab\nc")), Exactly("d"))
+
+	_, err := Parse(parser, "ab\ncx")
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected ParseError, got %v", err)
+	}
+	if pe.Offset != 4 {
+		t.Errorf("expected offset 4, got %d", pe.Offset)
+	}
+	if pe.Line != 2 || pe.Column != 2 {
+		t.Errorf("expected position 2:2, got %d:%d", pe.Line, pe.Column)
+	}
+	if !errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected error to be ErrNoMatch")
+	}
+}
+
+func TestParseError_unconsumed_input(t *testing.T) {
+	_, err := Parse(Exactly("a"), "ab")
+	if !errors.Is(err, ErrUnconsumedInput) {
+		t.Errorf("expected ErrUnconsumedInput, got %v", err)
+	}
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected ParseError, got %v", err)
+	}
+	if pe.Offset != 1 {
+		t.Errorf("expected offset 1, got %d", pe.Offset)
+	}
+}
+
+func TestParseError_labels(t *testing.T) {
+	parser := WithLabel(WithLabel(Exactly("a"), "inner"), "outer")
+
+	_, err := Parse(parser, "b")
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected ParseError, got %v", err)
+	}
+	if len(pe.Labels) != 2 || pe.Labels[0].Label != "outer" || pe.Labels[1].Label != "inner" {
+		t.Errorf("expected labels [outer inner], got %v", pe.Labels)
+	}
+	expected := "error parsing outer: error parsing inner: expected outer at 1:1"
+	if err.Error() != expected {
+		t.Errorf("expected '%s', got '%s'", expected, err.Error())
+	}
+}
+
+func TestParseError_expected(t *testing.T) {
+	number := WithLabel(ConsumeSome(IsDecimalDigit), "number")
+	parser := OneOf(Exactly("true"), Exactly("false"), number)
+
+	_, err := Parse(parser, "blue")
+	expected := `expected "true", "false" or number at 1:1`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%s', got '%v'", expected, err)
+	}
+}
+
+func TestParseError_furthest(t *testing.T) {
+	parser := OneOf(
+		StartSkipping(Exactly("ab")),
+		AppendSkipping(StartSkipping(Exactly("a")), Exactly("c")),
+		AppendSkipping(StartSkipping(Exactly("a")), Exactly("d")),
+	)
+
+	_, err := Parse(parser, "ax")
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected ParseError, got %v", err)
+	}
+	if pe.Offset != 1 {
+		t.Errorf("expected offset 1, got %d", pe.Offset)
+	}
+	expected := `expected "c" or "d" at 1:2`
+	if err.Error() != expected {
+		t.Errorf("expected '%s', got '%s'", expected, err.Error())
+	}
+}
+
+func TestParseError_furthest_labels(t *testing.T) {
+	number := WithLabel(ConsumeSome(IsDecimalDigit), "number")
+	str := WithLabel(AppendSkipping(Exactly(`"`), Exactly(`"`)), "string")
+	value := WithLabel(OneOf(number, str, Exactly("true")), "value")
+
+	_, err := Parse(OneOf(number, str, Exactly("true")), "x")
+	expected := `expected number, string or "true" at 1:1`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%s', got '%v'", expected, err)
+	}
+
+	_, err = Parse(AppendSkipping(Exactly("["), value), "[x")
+	expected = "error parsing value: expected value at 1:2"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%s', got '%v'", expected, err)
+	}
+
+	list := WithLabel(OneOf(AppendSkipping(Exactly("["), number), AppendSkipping(Exactly("["), str)), "list")
+	_, err = Parse(list, "[x")
+	expected = "error parsing list: expected number or string at 1:2"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%s', got '%v'", expected, err)
+	}
+}
+
+func TestParseError_label_frames(t *testing.T) {
+	entry := WithLabel(AppendSkipping(StartSkipping(Exactly("a")), Exactly(";")), "entry")
+	parser := WithLabel(AppendSkipping(StartSkipping(Exactly("{")), entry), "object")
+
+	_, err := Parse(parser, "{a:")
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected ParseError, got %v", err)
+	}
+	if pe.Labels[0].Offset != 0 || pe.Labels[1].Offset != 1 {
+		t.Errorf("expected label offsets 0 and 1, got %v", pe.Labels)
+	}
+	if !errors.Is(errors.Unwrap(err), ErrNoMatch) {
+		t.Errorf("expected unwrapped error to be ErrNoMatch")
+	}
+
+	expectMessage := func(mode LabelMode, expected string) {
+		if actual := pe.Message(mode); actual != expected {
+			t.Errorf("expected '%s', got '%s'", expected, actual)
+		}
+	}
+	expectMessage(LabelsAll, `error parsing object: error parsing entry: expected ";" at 1:3`)
+	expectMessage(LabelsInnermost, `error parsing entry: expected ";" at 1:3`)
+	expectMessage(LabelsOutermost, `error parsing object: expected ";" at 1:3`)
+	expectMessage(LabelsNone, `expected ";" at 1:3`)
+}
+
+func TestWithLabel_custom_error(t *testing.T) {
+	custom := errors.New("custom")
+	parser := WithLabel(func(initial State) (Empty, State, error) {
+		return empty, initial, custom
+	}, "custom parser")
+
+	_, err := Parse(parser, "")
+	if !errors.Is(err, custom) {
+		t.Errorf("expected error to wrap custom error, got %v", err)
+	}
+}
+
+func TestParseError_unconsumed_input_furthest(t *testing.T) {
+	value := OneOf(StartSkipping(ConsumeSome(IsDecimalDigit)), StartSkipping(Exactly("true")))
+	parser := SepBy(value, AppendSkipping(StartSkipping(Exactly(",")), ConsumeWhile(IsWhitespace)))
+
+	_, err := Parse(parser, "1, 2;3")
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected ParseError, got %v", err)
+	}
+	if pe.Furthest == nil {
+		t.Fatalf("expected furthest failure")
+	}
+	if pe.Furthest.Offset != 4 {
+		t.Errorf("expected furthest failure at offset 4, got %d", pe.Furthest.Offset)
+	}
+	expected := `unconsumed input at 1:5 (furthest failure: expected "," at 1:5)`
+	if err.Error() != expected {
+		t.Errorf("expected '%s', got '%s'", expected, err.Error())
+	}
+}
@@ -1,23 +1,29 @@
 package examples
 
 import (
+	"fmt"
 	"github.com/cfichtmueller/paco"
 	"strconv"
 	"testing"
 )
 
 func Test_Integers(t *testing.T) {
-	intParser := paco.FlatMap(
-		paco.GetString(paco.ConsumeSome(paco.IsDecimalDigit)),
-		func(digits string) paco.Parser[int] {
-			if len(digits) > 1 && digits[0] == '0' {
-				return paco.Fail[int]
-			}
-			v, err := strconv.Atoi(digits)
-			if err != nil {
-				return paco.Fail[int]
-			}
-			return paco.Succeed(v)
+	intParser := paco.Map(
+		paco.Validate(
+			paco.GetString(paco.ConsumeSome(paco.IsDecimalDigit)),
+			func(digits string) error {
+				if len(digits) > 1 && digits[0] == '0' {
+					return fmt.Errorf("integer %s has leading zeros", digits)
+				}
+				if _, err := strconv.ParseInt(digits, 10, 64); err != nil {
+					return fmt.Errorf("integer %s overflows int64", digits)
+				}
+				return nil
+			},
+		),
+		func(digits string) int {
+			v, _ := strconv.ParseInt(digits, 10, 64)
+			return int(v)
 		},
 	)
 
@@ -46,4 +52,10 @@ func Test_Integers(t *testing.T) {
 	mustNotParse("00")
 	mustNotParse("abc")
 	mustNotParse("12c")
+
+	_, err := paco.Parse(intParser, "99999999999999999999")
+	expected := "integer 99999999999999999999 overflows int64 at 1:1"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%s', got '%v'", expected, err)
+	}
 }
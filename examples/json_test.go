@@ -16,6 +16,10 @@ func JsonValueFromSlice(v []JsonValue) JsonValue {
 	return v
 }
 
+func JsonValueFromEntries(entries []JsonEntry) JsonValue {
+	return entries
+}
+
 type JsonEntry struct {
 	Key   string
 	Value JsonValue
@@ -68,10 +72,12 @@ func Test_JSON(t *testing.T) {
 
 	numberParser := paco.Map(paco.GetString(paco.ConsumeSome(paco.IsDecimalDigit)), JsonValueFromString)
 	stringParser := paco.Map(paco.Between(paco.Exactly("\""), paco.GetString(paco.ConsumeWhile(paco.IsNoneOf('"', '\n', '\r'))), paco.Exactly("\"")), JsonValueFromString)
+	var objectParser paco.Parser[[]JsonEntry]
+	valueRef := paco.NewRef[JsonValue]("value")
 	arrayParser := paco.Map(paco.Between(
 		paco.Exactly("["),
 		paco.SepBy(
-			paco.OneOf(numberParser, stringParser),
+			valueRef.Parser(),
 			paco.Between(consumeWhitespaceOrNewline, paco.Exactly(","), consumeWhitespaceOrNewline),
 		),
 		paco.Exactly("]"),
@@ -79,7 +85,11 @@ func Test_JSON(t *testing.T) {
 	trueParser := paco.Map(paco.Exactly("true"), func(empty paco.Empty) JsonValue { return true })
 	falseParser := paco.Map(paco.Exactly("false"), func(empty paco.Empty) JsonValue { return false })
 	nullParser := paco.Map(paco.Exactly("null"), func(empty paco.Empty) JsonValue { return nil })
-	valueParser := paco.OneOf(numberParser, stringParser, arrayParser, trueParser, falseParser, nullParser)
+	objectValueParser := paco.Lazy(func() paco.Parser[JsonValue] {
+		return paco.Map(objectParser, JsonValueFromEntries)
+	})
+	valueParser := paco.OneOf(numberParser, stringParser, arrayParser, trueParser, falseParser, nullParser, objectValueParser)
+	valueRef.Set(valueParser)
 
 	v1, err := paco.Parse(valueParser, "0815")
 	if err != nil {
@@ -155,7 +165,7 @@ func Test_JSON(t *testing.T) {
 		t.Errorf("entries parser didn't parse multiple entries (containing newline): %v", err)
 	}
 
-	objectParser := paco.Between(
+	objectParser = paco.Between(
 		startObjectParser,
 		entriesParser,
 		endObjectParser,
@@ -191,4 +201,14 @@ func Test_JSON(t *testing.T) {
 	mustParseObject("object2.json", object2Json, 1)
 	mustParseObject("object3.json", object3Json, 3)
 	mustParseObject("object4.json", object4Json, 6)
+	mustParseObject("nested object", "{\"matrix\": [[1, 2], [\"x\", [3]]], \"child\": {\"name\": \"jon\"}}", 2)
+
+	entries, err = paco.Parse(objectParser, "{\"child\": {\"name\": \"jon\"}}")
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("object parser didn't parse nested object: %v", err)
+	}
+	child, ok := entries[0].Value.([]JsonEntry)
+	if !ok || len(child) != 1 || child[0].Value != "jon" {
+		t.Errorf("expected nested object with name jon, got %v", entries[0].Value)
+	}
 }
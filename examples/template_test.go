@@ -74,12 +74,11 @@ func Test_template_parser(t *testing.T) {
 
 func createTemplateParser() paco.Parser[Template] {
 	templateParser := paco.Map(
-		paco.RepeatWhile(
+		paco.Many(
 			paco.OneOf(
 				createLiteralParser(),
 				createPlaceholderParser(),
 			),
-			func(part TemplatePart) bool { return true },
 		),
 		func(p []TemplatePart) Template {
 			return Template{Parts: p}
@@ -125,6 +124,13 @@ func Test_template_literal_parser(t *testing.T) {
 		t.Errorf("literalParser didn't parse: %v", err)
 	}
 
+	literal, err := paco.Parse(parser, "a { b }")
+	if err != nil {
+		t.Errorf("literalParser didn't parse single brace: %v", err)
+	} else if actual := literal(nil); actual != "a { b }" {
+		t.Errorf("Expected 'a { b }', got '%s'", actual)
+	}
+
 	_, next, err := parser(paco.State{
 		Data:   "{{foo}}",
 		Offset: 0,
@@ -139,7 +145,15 @@ func Test_template_literal_parser(t *testing.T) {
 
 func createLiteralParser() paco.Parser[TemplatePart] {
 	literalParser := paco.Map(
-		paco.GetString(paco.ConsumeSome(paco.IsNoneOf('{'))),
+		paco.Validate(
+			paco.TakeUntil(paco.OneOf(paco.Exactly("{{"), paco.EOF)),
+			func(literal string) error {
+				if literal == "" {
+					return paco.ErrNoMatch
+				}
+				return nil
+			},
+		),
 		func(literal string) TemplatePart { return func(c TemplateContext) string { return literal } },
 	)
 	return literalParser
@@ -0,0 +1,141 @@
+package paco
+
+import (
+	"sort"
+	"sync"
+)
+
+// Pos is a position within a FileSet. Positions of different files never overlap, so a Pos identifies the file it
+// belongs to. The zero value NoPos is not a valid position.
+type Pos int
+
+const NoPos Pos = 0
+
+// Span is the range of positions [Start, End) covered by a parser
+type Span struct {
+	Start Pos
+	End   Pos
+}
+
+// File is an input registered with a FileSet
+type File struct {
+	name string
+	base int
+	data string
+}
+
+// Name returns the name the file was registered with
+func (f *File) Name() string {
+	return f.name
+}
+
+// Data returns the content of the file
+func (f *File) Data() string {
+	return f.data
+}
+
+// Pos returns the position of the given byte offset within the file
+func (f *File) Pos(offset int) Pos {
+	return Pos(f.base + offset)
+}
+
+// Position returns the position of the given byte offset within the file. Options like WithTabWidth affect the
+// column, so pass the options the file was parsed with.
+func (f *File) Position(offset int, opts ...Option) Position {
+	return State{Data: f.data, Offset: offset, file: f, env: newEnvironment(opts)}.Position()
+}
+
+// FileSet is a registry of inputs, in the spirit of go/token.FileSet. Parse files with ParseFile or Include, and
+// use Position to resolve positions of errors and spans to file, line and column. A FileSet is safe for
+// concurrent use.
+type FileSet struct {
+	mu    sync.RWMutex
+	base  int
+	files []*File
+}
+
+// NewFileSet creates an empty FileSet
+func NewFileSet() *FileSet {
+	return &FileSet{base: 1}
+}
+
+// AddFile registers an input with the given name
+func (s *FileSet) AddFile(name, data string) *File {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := &File{name: name, base: s.base, data: data}
+	// one extra position for the end of the file
+	s.base += len(data) + 1
+	s.files = append(s.files, f)
+	return f
+}
+
+// File returns the file containing the given position or nil if there is none
+func (s *FileSet) File(p Pos) *File {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := sort.Search(len(s.files), func(i int) bool { return s.files[i].base > int(p) }) - 1
+	if i < 0 || int(p) > s.files[i].base+len(s.files[i].data) {
+		return nil
+	}
+	return s.files[i]
+}
+
+// Position resolves the given position. It returns the zero Position if p doesn't belong to a file of the set.
+// Options like WithTabWidth affect the column, so pass the options the file was parsed with.
+func (s *FileSet) Position(p Pos, opts ...Option) Position {
+	f := s.File(p)
+	if f == nil {
+		return Position{}
+	}
+	return f.Position(int(p)-f.base, opts...)
+}
+
+// Pos returns the position of the current offset within its FileSet or NoPos if the input is not a File
+func (s State) Pos() Pos {
+	if s.file == nil {
+		return NoPos
+	}
+	return s.file.Pos(s.Offset)
+}
+
+// GetSpan runs the given parser and returns its result together with the span of input it consumed
+func GetSpan[T any](parser Parser[T]) Parser[Tuple[T, Span]] {
+	return func(initial State) (Tuple[T, Span], State, error) {
+		t, next, err := parser(initial)
+		if err != nil {
+			var zero Tuple[T, Span]
+			return zero, initial, err
+		}
+		return Tuple[T, Span]{A: t, B: Span{Start: initial.Pos(), End: next.Pos()}}, next, nil
+	}
+}
+
+// Include parses the whole content of file with the given parser without consuming input, e.g. to process an
+// include directive. Errors refer to the included file and are fatal, as offsets of different files can't be
+// compared when choosing the furthest failure. Warnings, recovered errors and the user state are carried over to
+// the including input.
+func Include[T any](file *File, parser Parser[T]) Parser[T] {
+	return func(initial State) (T, State, error) {
+		included := initial
+		included.Data = file.data
+		included.Offset = 0
+		included.pos = startPosition
+		included.file = file
+		included.bytes = nil
+		included.src = nil
+		included.failure = nil
+		t, final, err := parser(included)
+		if err == nil && final.HasRemaining() {
+			err = unconsumedInput(final)
+		}
+		if err != nil {
+			return t, initial, asParseError(final, err).fatal()
+		}
+		next := initial
+		next.warnings = final.warnings
+		next.diagnostics = final.diagnostics
+		next.user = final.user
+		return t, next, nil
+	}
+}
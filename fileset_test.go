@@ -0,0 +1,97 @@
+package paco
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFileSet_Position(t *testing.T) {
+	fset := NewFileSet()
+	a := fset.AddFile("a.conf", "x\ny")
+	b := fset.AddFile("b.conf", "z")
+
+	expectPosition := func(p Pos, expected string) {
+		if actual := fset.Position(p).String(); actual != expected {
+			t.Errorf("expected %s, got %s", expected, actual)
+		}
+	}
+	expectPosition(a.Pos(0), "a.conf:1:1")
+	expectPosition(a.Pos(2), "a.conf:2:1")
+	expectPosition(a.Pos(3), "a.conf:2:2")
+	expectPosition(b.Pos(0), "b.conf:1:1")
+	expectPosition(b.Pos(1), "b.conf:1:2")
+
+	if fset.File(NoPos) != nil {
+		t.Errorf("expected no file for NoPos")
+	}
+}
+
+// parseIncluding parses main.conf including other.conf with the given contents
+func parseIncluding(main, other string) ([]string, *FileSet, error) {
+	fset := NewFileSet()
+	mainFile := fset.AddFile("main.conf", main)
+	files := map[string]*File{"other.conf": fset.AddFile("other.conf", other)}
+
+	var statements Parser[[]string]
+	include := FlatMap(
+		Unpack(AppendKeeping(StartSkipping(Exactly("include ")), GetString(ConsumeSome(IsNoneOf(';'))))),
+		func(name string) Parser[string] {
+			file, ok := files[name]
+			if !ok {
+				return FailWith[string]("unknown file %s", name)
+			}
+			return Map(Include(file, statements), func(s []string) string { return strings.Join(s, "") })
+		},
+	)
+	statement := AppendSkipping(
+		AppendSkipping(OneOf(include, GetString(ConsumeSome(IsAsciiLetter))), Exactly(";")),
+		ConsumeWhile(IsAnyOf('\n')),
+	)
+	statements = RepeatWhile(statement, func(string) bool { return true })
+
+	r, err := ParseFile(statements, mainFile)
+	return r, fset, err
+}
+
+func TestParseFile_include(t *testing.T) {
+	_, fset, err := parseIncluding("a;include other.conf;b;", "c;\nd!")
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected ParseError, got %v", err)
+	}
+	if pe.Filename != "other.conf" {
+		t.Errorf("expected error in other.conf, got %s", pe.Filename)
+	}
+	if actual := fset.Position(pe.Pos).String(); actual != "other.conf:2:2" {
+		t.Errorf("expected error at other.conf:2:2, got %s", actual)
+	}
+	expected := `expected ";" at other.conf:2:2`
+	if err.Error() != expected {
+		t.Errorf("expected '%s', got '%s'", expected, err.Error())
+	}
+
+	r, _, err := parseIncluding("a;include other.conf;b;", "c;\nd;")
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if strings.Join(r, " ") != "a cd b" {
+		t.Errorf("expected [a cd b], got %v", r)
+	}
+}
+
+func TestGetSpan(t *testing.T) {
+	fset := NewFileSet()
+	fset.AddFile("first", "abc")
+	file := fset.AddFile("second", "ab cd")
+	parser := AppendKeeping(StartSkipping(Exactly("ab ")), GetSpan(Exactly("cd")))
+
+	r, err := ParseFile(parser, file)
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	span := r.B.B
+	if fset.Position(span.Start).String() != "second:1:4" || fset.Position(span.End).String() != "second:1:6" {
+		t.Errorf("expected span second:1:4-second:1:6, got %s-%s", fset.Position(span.Start), fset.Position(span.End))
+	}
+}
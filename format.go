@@ -0,0 +1,113 @@
+package paco
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[31m"
+	ansiBlue  = "\x1b[34m"
+)
+
+// FormatOptions configures how FormatError renders errors
+type FormatOptions struct {
+	// Color enables ANSI colors
+	Color bool
+	// ContextLines is the number of source lines shown before and after the failing line
+	ContextLines int
+	// Labels selects which labels of the label chain are shown
+	Labels LabelMode
+	// FileSet resolves the source of errors in Files of the set, e.g. of included files. The snippet is taken from
+	// the file the error occurred in instead of data then.
+	FileSet *FileSet
+}
+
+// FormatError renders the given error as a source snippet of data, or of its file if FileSet is set, with a caret
+// under the failing column, followed by the label chain. Errors that are not a *ParseError are rendered with their
+// plain message.
+func FormatError(err error, data string, opts FormatOptions) string {
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		return err.Error()
+	}
+	if opts.FileSet != nil && pe.Pos != NoPos {
+		if file := opts.FileSet.File(pe.Pos); file != nil {
+			data = file.Data()
+		}
+	}
+	f := formatter{opts: opts}
+	lines := splitLines(data)
+	first := pe.Line - opts.ContextLines
+	if first < 1 {
+		first = 1
+	}
+	last := pe.Line + opts.ContextLines
+	if last > len(lines) {
+		last = len(lines)
+	}
+	gutter := len(fmt.Sprint(last))
+
+	b := strings.Builder{}
+	b.WriteString(f.style(ansiBold+ansiRed, "error"))
+	b.WriteString(f.style(ansiBold, ": "+pe.message()))
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "%s%s\n", strings.Repeat(" ", gutter), f.style(ansiBlue, "--> "+pe.position()))
+	b.WriteString(f.gutter(gutter, ""))
+	b.WriteString("\n")
+	for n := first; n <= last; n++ {
+		b.WriteString(f.gutter(gutter, fmt.Sprint(n)))
+		b.WriteString(" ")
+		b.WriteString(lines[n-1])
+		b.WriteString("\n")
+		if n == pe.Line {
+			b.WriteString(f.gutter(gutter, ""))
+			b.WriteString(" ")
+			b.WriteString(caretIndent(data, pe.Offset))
+			b.WriteString(f.style(ansiBold+ansiRed, "^"))
+			b.WriteString("\n")
+		}
+	}
+	if labels := pe.labels(opts.Labels); len(labels) > 0 {
+		b.WriteString(f.gutter(gutter, ""))
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "%s %s while parsing %s\n", strings.Repeat(" ", gutter), f.style(ansiBlue, "="), strings.Join(labels, " > "))
+	}
+	if pe.Furthest != nil {
+		fmt.Fprintf(&b, "%s %s furthest failure: %s\n", strings.Repeat(" ", gutter), f.style(ansiBlue, "="), pe.Furthest.Message(opts.Labels))
+	}
+	return b.String()
+}
+
+type formatter struct {
+	opts FormatOptions
+}
+
+func (f formatter) style(code, s string) string {
+	if !f.opts.Color {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+func (f formatter) gutter(width int, lineNumber string) string {
+	return f.style(ansiBlue, fmt.Sprintf("%*s |", width, lineNumber))
+}
+
+// caretIndent returns the whitespace needed to place a caret under the given offset. Tabs of the source line are
+// kept so the caret lines up regardless of the tab width of the terminal.
+func caretIndent(data string, offset int) string {
+	start := strings.LastIndexAny(data[:offset], "\r\n") + 1
+	b := strings.Builder{}
+	for _, r := range data[start:offset] {
+		if r == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	return b.String()
+}
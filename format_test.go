@@ -0,0 +1,58 @@
+package paco
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFormatError(t *testing.T) {
+	entry := WithLabel(AppendSkipping(AppendSkipping(StartSkipping(ConsumeWhile(IsWhitespace)), Exactly("a")), Exactly(";")), "entry")
+	parser := WithLabel(SepBy(entry, Exactly("\n")), "entries")
+	data := "a;\n\ta:\na;"
+
+	_, err := Parse(parser, data)
+	if err == nil {
+		t.Fatalf("parser parsed invalid input")
+	}
+
+	actual := FormatError(err, data, FormatOptions{ContextLines: 1})
+	expected := `error: expected ";"
+ --> 2:3
+  |
+1 | a;
+2 | 	a:
+  | 	 ^
+3 | a;
+  |
+  = while parsing entries > entry
+`
+	if actual != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, actual)
+	}
+}
+
+func TestFormatError_file_set(t *testing.T) {
+	main := "a;include other.conf;b;"
+	_, fset, err := parseIncluding(main, "c;\nd!")
+	if err == nil {
+		t.Fatalf("parser parsed invalid input")
+	}
+
+	actual := FormatError(err, main, FormatOptions{FileSet: fset})
+	expected := `error: expected ";"
+ --> other.conf:2:2
+  |
+2 | d!
+  |  ^
+`
+	if actual != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, actual)
+	}
+}
+
+func TestFormatError_plain_error(t *testing.T) {
+	actual := FormatError(errors.New("boom"), "", FormatOptions{})
+	if actual != "boom" {
+		t.Errorf("expected 'boom', got '%s'", actual)
+	}
+}
@@ -1,26 +1,135 @@
 package paco
 
 import (
+	"context"
 	"fmt"
-	"strings"
+	"io"
+	"strconv"
+	"sync"
 )
 
 // Parse is the main parsing function. Provide a parser and an input and receive the parsing result.
-func Parse[T any](parser Parser[T], data string) (T, error) {
-	initial := State{
-		Data:   data,
-		Offset: 0,
+// Errors are returned as *ParseError. If the parser recovered from errors (see Recover), Parse returns the
+// partial result together with an ErrorList containing all recovered errors. If parsing fails after recovering
+// from errors, the ErrorList contains the recovered errors followed by the error parsing failed with. Options like
+// WithTrailingWhitespace or WithMaxSteps configure the run, all entry points accept them.
+func Parse[T any](parser Parser[T], data string, opts ...Option) (T, error) {
+	result, _, err := run(parser, State{Data: data, Offset: 0, env: newEnvironment(opts)})
+	return result, err
+}
+
+// ParseBytes works like Parse but parses a byte slice without copying it to a string. data must not be modified
+// while parsing. Strings returned by the parser, e.g. from GetString, are copies. Use GetBytes to get sub-slices of
+// data without copying, which share memory with data.
+func ParseBytes[T any](parser Parser[T], data []byte, opts ...Option) (T, error) {
+	initial := bytesState(data)
+	initial.env = newEnvironment(opts)
+	result, _, err := run(parser, initial)
+	return result, err
+}
+
+// ParseContext works like Parse but aborts once ctx is done, failing with the error of ctx at the position reached.
+// It is a shorthand for Parse with WithContext.
+// Options like WithMaxSteps and WithMaxBacktracks limit the work spent on untrusted input.
+func ParseContext[T any](ctx context.Context, parser Parser[T], data string, opts ...Option) (T, error) {
+	result, _, err := run(parser, State{Data: data, Offset: 0, env: newEnvironment(append([]Option{WithContext(ctx)}, opts...))})
+	return result, err
+}
+
+// ParsePrefix applies the parser to data without expecting it to consume all input. It returns the result together
+// with the state after it, whose Remaining method returns the unconsumed input.
+func ParsePrefix[T any](parser Parser[T], data string, opts ...Option) (T, State, error) {
+	return runPrefix(parser, State{Data: data, Offset: 0, env: newEnvironment(opts)})
+}
+
+// ParseWith parses data with the given options. It is equivalent to Parse, which accepts options as well.
+func ParseWith[T any](parser Parser[T], data string, opts ...Option) (T, error) {
+	return Parse(parser, data, opts...)
+}
+
+// ParseWithWarnings works like Parse but additionally returns the warnings recorded along the successful path.
+// Warnings recorded in branches that were backtracked out of are discarded. Use WithWarningsTo to get the warnings
+// from other entry points.
+func ParseWithWarnings[T any](parser Parser[T], data string, opts ...Option) (T, []Warning, error) {
+	result, final, err := run(parser, State{Data: data, Offset: 0, env: newEnvironment(opts)})
+	return result, final.warnings.values(), err
+}
+
+// ParseFile works like Parse but parses a File of a FileSet. Errors carry the file name and a Pos within the set.
+// Pass the same options to File.Position and FileSet.Position, so they agree with the columns of errors.
+func ParseFile[T any](parser Parser[T], file *File, opts ...Option) (T, error) {
+	result, _, err := run(parser, State{Data: file.data, Offset: 0, file: file, env: newEnvironment(opts)})
+	return result, err
+}
+
+// ParseReader works like Parse but reads the input from r. Input is released as soon as no combinator can backtrack
+// to it anymore, e.g. between the items of Many, so memory usage is bounded by the input an enclosing combinator
+// may have to read again. Parsing fails with ErrBufferFull if the buffer grows beyond 64 MiB. Custom parsers must
+// only read a state again after applying a parser to it through a combinator like OneOf, Optional or Peek;
+// reading released input fails fatally with ErrReleasedInput.
+func ParseReader[T any](parser Parser[T], r io.Reader, opts ...Option) (T, error) {
+	result, _, err := run(parser, readerState(r, opts))
+	return result, err
+}
+
+// ParseWithUserState works like Parse but starts with the given initial value of the user state and additionally
+// returns its final value. See UserState.
+func ParseWithUserState[T, U any](parser Parser[T], data string, state UserState[U], initial U, opts ...Option) (T, U, error) {
+	result, final, err := run(parser, state.with(State{Data: data, Offset: 0, env: newEnvironment(opts)}, initial))
+	if err != nil {
+		return result, initial, err
 	}
+	return result, state.Value(final), nil
+}
+
+// run applies the parser to the initial state and expects it to consume all input
+func run[T any](parser Parser[T], initial State) (T, State, error) {
+	return apply(parser, initial, true)
+}
+
+// runPrefix applies the parser to the initial state without expecting it to consume all input
+func runPrefix[T any](parser Parser[T], initial State) (T, State, error) {
+	return apply(parser, initial, false)
+}
+
+// apply applies the parser to the initial state and collects the errors of the run. If all is set, the parser is
+// expected to consume all input.
+func apply[T any](parser Parser[T], initial State, all bool) (T, State, error) {
+	var zero T
 	result, final, err := parser(initial)
+	initial.env.collectWarnings(final)
+	if inputErr := initial.inputError(); inputErr != nil {
+		return zero, final, initial.env.finishError(newError(final, inputErr))
+	}
 	if err != nil {
-		var zero T
-		return zero, err
+		return zero, final, initial.env.finishFailure(asParseError(final, err))
 	}
-	if final.Offset < len(final.Data) {
-		var zero T
-		return zero, ErrUnconsumedInput
+	if all {
+		if final.env != nil && final.env.trailingWhitespace {
+			_, final, _ = ConsumeWhile(isTrailingWhitespace)(final)
+		}
+		if final.HasRemaining() {
+			return zero, final, final.env.finishFailure(unconsumedInput(final))
+		}
+	}
+	if errs := final.errors(); len(errs) > 0 {
+		return result, final, initial.env.finishErrors(errs)
+	}
+	return result, final, nil
+}
+
+func isTrailingWhitespace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// unconsumedInput creates the error for input left over in the final state. It carries the furthest failure that
+// stopped the parser from consuming more.
+func unconsumedInput(final State) *ParseError {
+	err := newError(final, ErrUnconsumedInput)
+	if final.failure != nil && final.failure.Offset >= final.Offset {
+		err.Furthest = final.failure
 	}
-	return result, nil
+	return err
 }
 
 // AppendKeeping runs parserT and parserU in order and returns a Tuple containing the values of both parsers.
@@ -57,6 +166,16 @@ func AppendSkipping[T, U any](parserT Parser[T], parserU Parser[U]) Parser[T] {
 	}
 }
 
+// AtLeast applies parser at least n times, see Repeat
+func AtLeast[T any](parser Parser[T], n int) Parser[[]T] {
+	return Repeat(parser, n, -1)
+}
+
+// AtMost applies parser at most n times, see Repeat
+func AtMost[T any](parser Parser[T], n int) Parser[[]T] {
+	return Repeat(parser, 0, n)
+}
+
 // Between runs parsers p1, p2, p3 in order and returns the result of p2
 func Between[T, U, A any](p1 Parser[T], p2 Parser[A], p3 Parser[U]) Parser[A] {
 	return func(initial State) (A, State, error) {
@@ -79,14 +198,24 @@ func Between[T, U, A any](p1 Parser[T], p2 Parser[A], p3 Parser[U]) Parser[A] {
 	}
 }
 
-// ConsumeIf consumes a rune if the condition holds true. If not it returns ErrNoMatch
+// ConsumeIf consumes a rune if the condition holds true. If not or at the end of the input it returns ErrNoMatch.
+// Invalid UTF-8 is handled as configured with WithInvalidUTF8.
 func ConsumeIf(condition func(rune) bool) Parser[Empty] {
 	return func(initial State) (Empty, State, error) {
-		r, next := initial.NextRune()
+		if err := initial.step(); err != nil {
+			return empty, initial, err
+		}
+		if !initial.HasRemaining() {
+			return empty, initial, newError(initial, ErrNoMatch)
+		}
+		r, next, err := initial.nextRune()
+		if err != nil {
+			return empty, initial, err
+		}
 		if condition(r) {
 			return empty, next, nil
 		}
-		return empty, initial, ErrNoMatch
+		return empty, initial, newError(initial, ErrNoMatch)
 	}
 }
 
@@ -97,11 +226,18 @@ func ConsumeSome(condition func(rune) bool) Parser[Empty] {
 }
 
 // ConsumeWhile consumes runes for as long as the condition holds true. May consume no runes.
+// Invalid UTF-8 is handled as configured with WithInvalidUTF8.
 func ConsumeWhile(condition func(rune) bool) Parser[Empty] {
 	return func(initial State) (Empty, State, error) {
+		if err := initial.step(); err != nil {
+			return empty, initial, err
+		}
 		current := initial
 		for current.HasRemaining() {
-			r, next := current.NextRune()
+			r, next, err := current.nextRune()
+			if err != nil {
+				return empty, initial, err
+			}
 			if !condition(r) {
 				return empty, current, nil
 			}
@@ -111,11 +247,44 @@ func ConsumeWhile(condition func(rune) bool) Parser[Empty] {
 	}
 }
 
-// Exactly consumes the given token. If it cans, it returns ErrNoMatch
+// Count applies parser exactly n times, see Repeat
+func Count[T any](parser Parser[T], n int) Parser[[]T] {
+	return Repeat(parser, n, n)
+}
+
+// Cut marks all errors of the given parser as fatal. Fatal errors stop backtracking: OneOf doesn't try further
+// alternatives and repetitions like SepBy propagate them instead of ending the repetition. Use it after a prefix
+// that unambiguously selects a construct, e.g. the rest of an object after "{".
+func Cut[T any](parser Parser[T]) Parser[T] {
+	return func(initial State) (T, State, error) {
+		t, next, err := parser(initial)
+		if err != nil {
+			return t, initial, asParseError(initial, err).fatal()
+		}
+		return t, next, nil
+	}
+}
+
+// EOF succeeds only at the end of the input
+func EOF(initial State) (Empty, State, error) {
+	if err := initial.step(); err != nil {
+		return empty, initial, err
+	}
+	if initial.HasRemaining() {
+		return empty, initial, newError(initial, ErrNoMatch).withExpected("end of input")
+	}
+	return empty, initial, nil
+}
+
+// Exactly consumes the given token. If it cans, it returns ErrNoMatch expecting the quoted token
 func Exactly(token string) Parser[Empty] {
+	expected := strconv.Quote(token)
 	return func(initial State) (Empty, State, error) {
-		if !strings.HasPrefix(initial.Remaining(), token) {
-			return empty, initial, ErrNoMatch
+		if err := initial.step(); err != nil {
+			return empty, initial, err
+		}
+		if !initial.hasPrefix(token) {
+			return empty, initial, newError(initial, ErrNoMatch).withExpected(expected)
 		}
 		next := initial.Consume(len(token))
 		return empty, next, nil
@@ -125,7 +294,15 @@ func Exactly(token string) Parser[Empty] {
 // Fail fails parsing with ErrNoMatch
 func Fail[T any](initial State) (T, State, error) {
 	var zero T
-	return zero, initial, ErrNoMatch
+	return zero, initial, newError(initial, ErrNoMatch)
+}
+
+// FailWith fails parsing with an error built from format and args, positioned at the current offset
+func FailWith[T any](format string, args ...any) Parser[T] {
+	return func(initial State) (T, State, error) {
+		var zero T
+		return zero, initial, newError(initial, fmt.Errorf(format, args...))
+	}
 }
 
 // FlatMap works like map but allows the mapper to decide whether to succeed or fail the operation.
@@ -141,16 +318,45 @@ func FlatMap[T, U any](parser Parser[T], mapper func(T) Parser[U]) Parser[U] {
 	}
 }
 
+// FollowedBy runs parser and succeeds only if next matches afterwards. next doesn't consume any input.
+func FollowedBy[T, U any](parser Parser[T], next Parser[U]) Parser[T] {
+	return AppendSkipping(parser, Peek(next))
+}
+
+// GetBytes returns a byte slice containing all bytes consumed by the given parser. When parsing with ParseBytes, the
+// slice is a sub-slice of the input, otherwise it is a copy.
+func GetBytes[T any](parser Parser[T]) Parser[[]byte] {
+	return func(initial State) ([]byte, State, error) {
+		initial.mark()
+		defer initial.unmark()
+		start := initial.Offset
+		_, next, err := parser(initial)
+		if err != nil {
+			return nil, initial, err
+		}
+		b, err := initial.sliceBytes(start, next.Offset)
+		if err != nil {
+			return nil, initial, newError(initial, err).fatal()
+		}
+		return b, next, nil
+	}
+}
+
 // GetString returns a string containing all characters consumed by the given parser
 func GetString[T any](parser Parser[T]) Parser[string] {
 	return func(initial State) (string, State, error) {
+		initial.mark()
+		defer initial.unmark()
 		start := initial.Offset
 		_, next, err := parser(initial)
 		if err != nil {
 			return "", initial, err
 		}
-		end := next.Offset
-		return initial.Data[start:end], next, nil
+		str, err := initial.slice(start, next.Offset)
+		if err != nil {
+			return "", initial, newError(initial, err).fatal()
+		}
+		return str, next, nil
 	}
 }
 
@@ -171,6 +377,29 @@ func Infix[T1, U, T2 any](left Parser[T1], infix Parser[U], right Parser[T2]) Pa
 	})
 }
 
+// Lazy defers building a parser until it is first applied, so rules can refer to parsers that are defined later.
+// Entering it counts as nesting a rule named lazy, see LazyNamed. Parsing fails fatally with ErrUnsetRef if build
+// returns nil.
+func Lazy[T any](build func() Parser[T]) Parser[T] {
+	return LazyNamed("lazy", build)
+}
+
+// LazyNamed works like Lazy but names the rule, so depth errors and traces tell which rule is nested too deep
+func LazyNamed[T any](name string, build func() Parser[T]) Parser[T] {
+	var once sync.Once
+	var parser Parser[T]
+	return Rule(name, func(initial State) (T, State, error) {
+		once.Do(func() {
+			parser = build()
+		})
+		if parser == nil {
+			var zero T
+			return zero, initial, newError(initial, fmt.Errorf("%w: rule %s built nil", ErrUnsetRef, name)).fatal()
+		}
+		return parser(initial)
+	})
+}
+
 // LeftAndRight parses left, sep, right and returns the values of left and right.
 // Useful for infix operator parsing where the operator value isn't needed
 func LeftAndRight[T1, U, T2 any](left Parser[T1], sep Parser[U], right Parser[T2]) Parser[Tuple[T1, T2]] {
@@ -180,6 +409,52 @@ func LeftAndRight[T1, U, T2 any](left Parser[T1], sep Parser[U], right Parser[T2
 	return Unpack2(p3)
 }
 
+// Many applies parser zero or more times, see Repeat
+func Many[T any](parser Parser[T]) Parser[[]T] {
+	return Repeat(parser, 0, -1)
+}
+
+// Many1 applies parser one or more times, see Repeat
+func Many1[T any](parser Parser[T]) Parser[[]T] {
+	return Repeat(parser, 1, -1)
+}
+
+// ManyTill applies parser until end matches and returns the collected results. end is consumed, but its result
+// is discarded. If neither end nor parser match, ManyTill fails with the error that got furthest into the input.
+func ManyTill[T, U any](parser Parser[T], end Parser[U]) Parser[[]T] {
+	return func(initial State) ([]T, State, error) {
+		current := initial
+		result := make([]T, 0)
+		for {
+			current.mark()
+			_, next, err := end(current)
+			current.unmark()
+			if err == nil {
+				return result, next, nil
+			}
+			if IsFatal(err) {
+				return nil, initial, err
+			}
+			failure := asParseError(current, err)
+			if err := current.step(); err != nil {
+				return nil, initial, err
+			}
+			t, next, err := parser(current)
+			if IsFatal(err) {
+				return nil, initial, err
+			}
+			if err != nil {
+				return nil, initial, furthest(failure, asParseError(current, err))
+			}
+			if next.Offset == current.Offset {
+				return nil, initial, failure
+			}
+			result = append(result, t)
+			current = next
+		}
+	}
+}
+
 // Map runs the given parser, then applies mapper to the result
 func Map[T, U any](parser Parser[T], mapper func(T) U) Parser[U] {
 	return func(initial State) (U, State, error) {
@@ -245,20 +520,157 @@ func MapT4[T, U, V, W, A any](parser Parser[Tuple[Tuple[Tuple[Tuple[Empty, T], U
 	}
 }
 
-// OneOf runs all given parsers in order, returns the result of the first parser that doesn't return an error
+// Not succeeds without consuming input if parser fails. If parser matches, Not fails with an error wrapping
+// ErrNoMatch that names the unexpected input. Fatal errors of parser are propagated.
+func Not[T any](parser Parser[T]) Parser[Empty] {
+	return func(initial State) (Empty, State, error) {
+		initial.mark()
+		defer initial.unmark()
+		_, next, err := parser(initial)
+		if IsFatal(err) {
+			return empty, initial, err
+		}
+		if err != nil {
+			return empty, initial, nil
+		}
+		text, _ := initial.slice(initial.Offset, next.Offset)
+		return empty, initial, newError(initial, unexpectedError{text: text})
+	}
+}
+
+// NotFollowedBy runs parser and succeeds only if next doesn't match afterwards, e.g. to keep keywords from
+// matching prefixes of identifiers. next doesn't consume any input.
+func NotFollowedBy[T, U any](parser Parser[T], next Parser[U]) Parser[T] {
+	return AppendSkipping(parser, Not(next))
+}
+
+// OneOf runs all given parsers in order, returns the result of the first parser that doesn't return an error.
+// If all parsers fail, it returns the error that got furthest into the input, merging the expected sets of all
+// errors at that offset.
 func OneOf[T any](parsers ...Parser[T]) Parser[T] {
 	return func(initial State) (T, State, error) {
-		err := ErrNoMatch
-		for _, p := range parsers {
-			var result T
-			var next State
-			result, next, err = p(initial)
+		initial.mark()
+		defer initial.unmark()
+		var failure *ParseError
+		for i, p := range parsers {
+			if i > 0 {
+				if err := initial.backtrack(); err != nil {
+					var zero T
+					return zero, initial, err
+				}
+			}
+			result, next, err := p(initial)
 			if err == nil {
-				return result, next, err
+				if failure != nil {
+					next = next.withFailure(failure)
+				}
+				return result, next, nil
+			}
+			if IsFatal(err) {
+				var zero T
+				return zero, initial, err
 			}
+			failure = furthest(failure, asParseError(initial, err))
+		}
+		if failure == nil {
+			failure = newError(initial, ErrNoMatch)
 		}
 		var zero T
-		return zero, initial, err
+		return zero, initial, failure
+	}
+}
+
+// Optional applies parser once if possible. It returns a pointer to the result or nil if parser failed. Fatal
+// errors are propagated.
+func Optional[T any](parser Parser[T]) Parser[*T] {
+	return func(initial State) (*T, State, error) {
+		initial.mark()
+		defer initial.unmark()
+		t, next, err := parser(initial)
+		if IsFatal(err) {
+			return nil, initial, err
+		}
+		if err != nil {
+			return nil, initial.withFailure(asParseError(initial, err)), nil
+		}
+		return &t, next, nil
+	}
+}
+
+// Peek runs parser and returns its result without consuming input
+func Peek[T any](parser Parser[T]) Parser[T] {
+	return func(initial State) (T, State, error) {
+		initial.mark()
+		defer initial.unmark()
+		t, _, err := parser(initial)
+		return t, initial, err
+	}
+}
+
+// Recover runs the given parser. If it fails, the error is recorded, input is skipped up to the point where sync
+// matches (without consuming sync) or the input ends, and placeholder is returned instead. Parse reports all
+// recorded errors. Errors recorded inside a branch that is backtracked out of are discarded. Recover returns the
+// error unrecorded if skipping wouldn't make progress, i.e. at the end of the input or if sync matches right away,
+// and never recovers from aborted parse runs (see ParseContext).
+func Recover[T, S any](parser Parser[T], sync Parser[S], placeholder T) Parser[T] {
+	return func(initial State) (T, State, error) {
+		initial.mark()
+		defer initial.unmark()
+		t, next, err := parser(initial)
+		if err == nil {
+			return t, next, nil
+		}
+		if initial.aborted() || !initial.HasRemaining() {
+			return t, initial, err
+		}
+		current := initial
+		for current.HasRemaining() {
+			if _, _, err := sync(current); err == nil {
+				break
+			} else if current.aborted() {
+				return t, initial, err
+			}
+			_, current = current.NextRune()
+		}
+		if current.Offset == initial.Offset {
+			return t, initial, err
+		}
+		return placeholder, current.withDiagnostic(asParseError(initial, err)), nil
+	}
+}
+
+// Repeat applies parser as often as possible, but at least min and at most max times. A negative max means no
+// upper bound. Repetition ends at the first failure or when parser stops consuming input. If parser succeeded less
+// than min times, Repeat fails with the error that ended the repetition. Fatal errors are always propagated.
+func Repeat[T any](parser Parser[T], min, max int) Parser[[]T] {
+	return func(initial State) ([]T, State, error) {
+		current := initial
+		result := make([]T, 0)
+		for max < 0 || len(result) < max {
+			current.mark()
+			t, next, err := parser(current)
+			current.unmark()
+			if IsFatal(err) {
+				return nil, initial, err
+			}
+			if err != nil {
+				failure := asParseError(current, err)
+				if len(result) < min {
+					return nil, initial, failure
+				}
+				return result, current.withFailure(failure), nil
+			}
+			result = append(result, t)
+			stuck := next.Offset == current.Offset
+			current = next
+			if stuck && max < 0 && len(result) >= min {
+				break
+			}
+		}
+		if len(result) < min {
+			return nil, initial, newError(current, ErrNoMatch)
+		}
+		return result, current, nil
 	}
 }
 
@@ -268,7 +680,9 @@ func RepeatWhile[T any](parser Parser[T], predicate func(T) bool) Parser[[]T] {
 		current := initial
 		result := make([]T, 0)
 		for current.HasRemaining() {
+			current.mark()
 			r, next, err := parser(current)
+			current.unmark()
 			if err != nil {
 				return nil, current, err
 			}
@@ -282,24 +696,58 @@ func RepeatWhile[T any](parser Parser[T], predicate func(T) bool) Parser[[]T] {
 	}
 }
 
-// WithLabel wraps the given parsers error messages with the given label
+// WithLabel adds a frame with the given label to the label stack of the given parsers errors, including the errors
+// recovered from inside it (see Recover). The cause stays accessible via errors.Is and errors.As. If the parser
+// fails with ErrNoMatch without consuming input, the label replaces the expected set of the error.
 func WithLabel[T any](p Parser[T], label string) Parser[T] {
 	return func(initial State) (T, State, error) {
+		frame := LabelFrame{Label: label, Offset: initial.Offset}
+		initial.traceEnter(label)
 		t, next, err := p(initial)
+		initial.traceLeave(label, next, err)
 		if err != nil {
-			return t, initial, fmt.Errorf("error parsing %s: %v", label, err)
+			pe := asParseError(initial, err)
+			if pe.Offset == initial.Offset && pe.isNoMatch() {
+				pe = pe.withExpected(label)
+			}
+			labeled := pe.withLabel(frame)
+			labeled.diagnostics = relabel(pe.diagnostics, initial.diagnostics, frame)
+			return t, initial, labeled
+		}
+		next.diagnostics = relabel(next.diagnostics, initial.diagnostics, frame)
+		return t, next, nil
+	}
+}
+
+// Rule marks a parser that may be entered recursively. Nesting rules deeper than the maximum depth (see
+// WithMaxDepth) fails fatally with ErrTooDeep instead of exhausting the stack.
+func Rule[T any](name string, parser Parser[T]) Parser[T] {
+	return func(initial State) (T, State, error) {
+		if maxDepth := initial.maxDepth(); maxDepth > 0 && initial.depth >= maxDepth {
+			var zero T
+			cause := fmt.Errorf("%w: rule %s is nested deeper than %d levels", ErrTooDeep, name, maxDepth)
+			return zero, initial, newError(initial, cause).fatal()
+		}
+		inner := initial
+		inner.depth++
+		initial.traceEnter(name)
+		t, next, err := parser(inner)
+		initial.traceLeave(name, next, err)
+		if err != nil {
+			return t, initial, err
 		}
+		next.depth = initial.depth
 		return t, next, nil
 	}
 }
 
-// SepBy parses zero or more p separated by sep
+// SepBy parses zero or more p separated by sep. Fatal errors of sep are propagated.
 func SepBy[T, A any](p Parser[A], sep Parser[T]) Parser[[]A] {
 	return func(initial State) ([]A, State, error) {
 		current := initial
 		result := make([]A, 0)
 		for {
-			if len(current.Remaining()) == 0 {
+			if !current.HasRemaining() {
 				return result, current, nil
 			}
 			val, afterVal, err := p(current)
@@ -307,9 +755,14 @@ func SepBy[T, A any](p Parser[A], sep Parser[T]) Parser[[]A] {
 				return nil, initial, err
 			}
 			result = append(result, val)
+			afterVal.mark()
 			_, afterSep, err := sep(afterVal)
+			afterVal.unmark()
+			if IsFatal(err) {
+				return nil, initial, err
+			}
 			if err != nil {
-				return result, afterVal, nil
+				return result, afterVal.withFailure(asParseError(afterVal, err)), nil
 			}
 			current = afterSep
 		}
@@ -328,7 +781,7 @@ func SepBy1[T, A any](p Parser[A], sep Parser[T]) Parser[[]A] {
 				return nil, initial, err
 			}
 			result = append(result, val)
-			if !first && len(next.Remaining()) == 0 {
+			if !first && !next.HasRemaining() {
 				return result, next, nil
 			}
 			_, next, err = sep(next)
@@ -341,6 +794,18 @@ func SepBy1[T, A any](p Parser[A], sep Parser[T]) Parser[[]A] {
 	}
 }
 
+// SkipUntil consumes input up to the point where end matches. end isn't consumed. It fails if end doesn't match
+// before the input ends.
+func SkipUntil[U any](end Parser[U]) Parser[Empty] {
+	return func(initial State) (Empty, State, error) {
+		before, _, err := scanUntil(initial, end)
+		if err != nil {
+			return empty, initial, err
+		}
+		return empty, before, nil
+	}
+}
+
 // StartKeeping returns a tuple with the result of the given parser
 func StartKeeping[T any](parser Parser[T]) Parser[Tuple[Empty, T]] {
 	return Map(parser, func(t T) Tuple[Empty, T] {
@@ -360,6 +825,67 @@ func Succeed[T any](value T) Parser[T] {
 	}
 }
 
+// TakeThrough consumes input up to and including the point where end matches and returns the consumed input,
+// including the input matched by end. It fails if end doesn't match before the input ends.
+func TakeThrough[U any](end Parser[U]) Parser[string] {
+	return func(initial State) (string, State, error) {
+		initial.mark()
+		defer initial.unmark()
+		_, after, err := scanUntil(initial, end)
+		if err != nil {
+			return "", initial, err
+		}
+		str, err := initial.slice(initial.Offset, after.Offset)
+		if err != nil {
+			return "", initial, newError(initial, err).fatal()
+		}
+		return str, after, nil
+	}
+}
+
+// TakeUntil consumes input up to the point where end matches and returns the consumed input. end isn't consumed,
+// e.g. the contents of a block comment are TakeUntil(Exactly("*/")). It fails if end doesn't match before the input
+// ends.
+func TakeUntil[U any](end Parser[U]) Parser[string] {
+	return func(initial State) (string, State, error) {
+		initial.mark()
+		defer initial.unmark()
+		before, _, err := scanUntil(initial, end)
+		if err != nil {
+			return "", initial, err
+		}
+		str, err := initial.slice(initial.Offset, before.Offset)
+		if err != nil {
+			return "", initial, newError(initial, err).fatal()
+		}
+		return str, before, nil
+	}
+}
+
+// scanUntil advances rune by rune until end matches. It returns the states before and after the match of end.
+// Every advance counts as a step, not as a backtrack, so WithMaxBacktracks doesn't limit the scanned length.
+func scanUntil[U any](initial State, end Parser[U]) (State, State, error) {
+	current := initial
+	for {
+		current.mark()
+		_, next, err := end(current)
+		current.unmark()
+		if err == nil {
+			return current, next, nil
+		}
+		if IsFatal(err) || !current.HasRemaining() {
+			return initial, initial, err
+		}
+		if err := current.step(); err != nil {
+			return initial, initial, err
+		}
+		_, current, err = current.nextRune()
+		if err != nil {
+			return initial, initial, err
+		}
+	}
+}
+
 // Unpack unpacks the tuple result of the given parser.
 func Unpack[T any](parser Parser[Tuple[Empty, T]]) Parser[T] {
 	return func(initial State) (T, State, error) {
@@ -386,3 +912,19 @@ func Unpack2[T, U any](parser Parser[Tuple[Tuple[Empty, T], U]]) Parser[Tuple[T,
 		}, next, nil
 	}
 }
+
+// Validate runs the given parser and passes its result to validator. If validator returns an error, parsing fails
+// with that error positioned at the start of the validated input.
+func Validate[T any](parser Parser[T], validator func(T) error) Parser[T] {
+	return func(initial State) (T, State, error) {
+		t, next, err := parser(initial)
+		if err != nil {
+			return t, initial, err
+		}
+		if err := validator(t); err != nil {
+			var zero T
+			return zero, initial, newError(initial, err)
+		}
+		return t, next, nil
+	}
+}
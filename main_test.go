@@ -1,6 +1,8 @@
 package paco
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -197,3 +199,451 @@ func Test_WithLabel(t *testing.T) {
 		t.Errorf("parser didn't return label: %v", err)
 	}
 }
+
+func TestCut(t *testing.T) {
+	object := AppendSkipping(StartSkipping(Exactly("{")), Cut(Exactly("}")))
+	parser := OneOf(object, StartSkipping(GetString(ConsumeSome(IsNoneOf('x')))))
+
+	_, err := Parse(parser, "{x")
+	if !IsFatal(err) {
+		t.Errorf("expected fatal error, got %v", err)
+	}
+	expected := `expected "}" at 1:2`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%s', got '%v'", expected, err)
+	}
+
+	_, err = Parse(parser, "ab")
+	if err != nil {
+		t.Errorf("parser didn't parse alternative: %v", err)
+	}
+}
+
+func TestCut_SepBy(t *testing.T) {
+	sep := AppendSkipping(StartSkipping(Exactly(",")), Cut(Exactly(" ")))
+	parser := SepBy(Exactly("a"), sep)
+
+	_, _, err := parser(State{Data: "a, a;", Offset: 0})
+	if err != nil {
+		t.Errorf("expected SepBy to stop without error, got %v", err)
+	}
+
+	_, _, err = parser(State{Data: "a,a", Offset: 0})
+	if !IsFatal(err) {
+		t.Errorf("expected fatal error, got %v", err)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	value := Recover(GetString(ConsumeSome(IsDecimalDigit)), Exactly(","), "?")
+	parser := SepBy(value, Exactly(","))
+
+	values, err := Parse(parser, "1,x,3,yy")
+	var errs ErrorList
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected ErrorList, got %v", err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %d", len(errs))
+	}
+	if errs[0].Offset != 2 || errs[1].Offset != 6 {
+		t.Errorf("expected errors at offsets 2 and 6, got %d and %d", errs[0].Offset, errs[1].Offset)
+	}
+	expected := []string{"1", "?", "3", "?"}
+	if strings.Join(values, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+func TestRecover_failure(t *testing.T) {
+	value := Recover(GetString(ConsumeSome(IsDecimalDigit)), Exactly(","), "?")
+	parser := AppendSkipping(SepBy(value, Exactly(",")), Exactly(";"))
+
+	_, err := Parse(parser, "1,x,3")
+	var errs ErrorList
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected ErrorList, got %v", err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected recovered and final error, got %v", errs)
+	}
+	if errs[0].Offset != 2 || errs[1].Error() != `expected ";" at 1:6` {
+		t.Errorf("expected error at offset 2 followed by 'expected \";\"', got %v", errs)
+	}
+}
+
+func TestRecover_no_progress(t *testing.T) {
+	parser := Many(Recover(Exactly("a"), Exactly(","), empty))
+
+	_, err := Parse(parser, "ab")
+	var errs ErrorList
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected ErrorList, got %v", err)
+	}
+	if len(errs) != 1 || errs[0].Error() != `expected "a" at 1:2` {
+		t.Errorf("expected a single error at 1:2, got %v", errs)
+	}
+}
+
+func TestRecover_backtracking(t *testing.T) {
+	value := Recover(Exactly("a"), Exactly(";"), empty)
+	parser := OneOf(
+		AppendSkipping(value, Exactly("!")),
+		StartSkipping(Exactly("b;")),
+	)
+
+	_, err := Parse(parser, "b;")
+	if err != nil {
+		t.Errorf("expected errors of backtracked branch to be discarded, got %v", err)
+	}
+}
+
+func TestFailWith(t *testing.T) {
+	parser := AppendSkipping(StartSkipping(Exactly("a")), FailWith[Empty]("%s is not allowed", "b"))
+
+	_, err := Parse(parser, "ab")
+	expected := "b is not allowed at 1:2"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%s', got '%v'", expected, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tooLong := errors.New("too long")
+	word := Validate(GetString(ConsumeSome(IsAsciiLetter)), func(s string) error {
+		if len(s) > 3 {
+			return tooLong
+		}
+		return nil
+	})
+	parser := AppendKeeping(StartSkipping(Exactly(" ")), word)
+
+	_, err := Parse(parser, " abc")
+	if err != nil {
+		t.Errorf("parser didn't parse: %v", err)
+	}
+
+	_, err = Parse(parser, " abcd")
+	if !errors.Is(err, tooLong) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+	expected := "too long at 1:2"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%s', got '%v'", expected, err)
+	}
+}
+
+func TestValidate_labeled(t *testing.T) {
+	tooLong := errors.New("too long")
+	word := Validate(GetString(ConsumeSome(IsAsciiLetter)), func(s string) error {
+		if len(s) > 3 {
+			return tooLong
+		}
+		return nil
+	})
+
+	_, err := Parse(WithLabel(word, "word"), "abcd")
+	expected := "error parsing word: too long at 1:1"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%s', got '%v'", expected, err)
+	}
+
+	_, err = Parse(OneOf(word, GetString(Exactly("1"))), "abcd")
+	expected = "too long at 1:1"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%s', got '%v'", expected, err)
+	}
+
+	_, err = Parse(OneOf(GetString(Exactly("1")), WithLabel(word, "word")), "abcd")
+	expected = "error parsing word: too long at 1:1"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected '%s', got '%v'", expected, err)
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	data := []byte("key=value")
+	parser := LeftAndRight(GetBytes(ConsumeSome(IsAsciiLetter)), Exactly("="), GetString(ConsumeSome(IsAsciiLetter)))
+
+	r, err := ParseBytes(parser, data)
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if string(r.A) != "key" || r.B != "value" {
+		t.Errorf("expected key=value, got %s=%s", r.A, r.B)
+	}
+	if &r.A[0] != &data[0] {
+		t.Errorf("expected GetBytes to return a sub-slice of the input")
+	}
+	copy(data, "KEY=VALUE")
+	if r.B != "value" {
+		t.Errorf("expected GetString to return a copy, got '%s' after modifying the input", r.B)
+	}
+
+	_, err = ParseBytes(parser, []byte("key:value"))
+	if err == nil || err.Error() != `expected "=" at 1:4` {
+		t.Errorf("expected error at 1:4, got %v", err)
+	}
+}
+
+func TestGetBytes_string_input(t *testing.T) {
+	r, err := Parse(GetBytes(Exactly("abc")), "abc")
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if string(r) != "abc" {
+		t.Errorf("expected 'abc', got '%s'", r)
+	}
+}
+
+func TestRule(t *testing.T) {
+	var array Parser[int]
+	array = Rule("array", func(initial State) (int, State, error) {
+		return Between(Exactly("["), OneOf(Map(array, func(depth int) int { return depth + 1 }), Succeed(0)), Exactly("]"))(initial)
+	})
+
+	depth, err := Parse(array, "[[[]]]")
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if depth != 2 {
+		t.Errorf("expected depth 2, got %d", depth)
+	}
+
+	deep := strings.Repeat("[", 100000) + strings.Repeat("]", 100000)
+	_, err = Parse(array, deep)
+	if !errors.Is(err, ErrTooDeep) {
+		t.Fatalf("expected ErrTooDeep, got %v", err)
+	}
+	expected := "maximum nesting depth exceeded: rule array is nested deeper than 1000 levels at 1:1001"
+	if err.Error() != expected {
+		t.Errorf("expected '%s', got '%s'", expected, err.Error())
+	}
+
+	_, err = ParseContext(context.Background(), array, "[[[[]]]]", WithMaxDepth(3))
+	if !errors.Is(err, ErrTooDeep) {
+		t.Errorf("expected ErrTooDeep, got %v", err)
+	}
+
+	_, err = Parse(array, strings.Repeat("[", 2000)+strings.Repeat("]", 2000), WithMaxDepth(0))
+	if err != nil {
+		t.Errorf("expected no depth limit, got %v", err)
+	}
+}
+
+func TestConsumeIf_end_of_input(t *testing.T) {
+	_, next, err := ConsumeIf(IsNoneOf('"'))(State{Data: "a", Offset: 1})
+	if err == nil {
+		t.Errorf("ConsumeIf matched at the end of the input")
+	}
+	if next.Offset != 1 {
+		t.Errorf("expected offset 1, got %d", next.Offset)
+	}
+}
+
+func TestConsumeWhile_invalid_utf8(t *testing.T) {
+	parser := GetString(ConsumeWhile(IsNoneOf('"')))
+	data := "a\xffb"
+
+	r, err := Parse(parser, data)
+	if err != nil || r != data {
+		t.Errorf("expected invalid byte to be replaced and accepted, got '%s' (%v)", r, err)
+	}
+
+	_, err = ParseContext(context.Background(), parser, data, WithInvalidUTF8(InvalidUTF8Reject))
+	if !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatalf("expected ErrInvalidUTF8, got %v", err)
+	}
+	if err.Error() != "invalid UTF-8 at 1:2" {
+		t.Errorf("expected error at 1:2, got %v", err)
+	}
+
+	notRaw := GetString(ConsumeWhile(func(r rune) bool { return !IsRawByte(r) }))
+	r, err = ParseContext(context.Background(), AppendSkipping(notRaw, ConsumeWhile(IsRawByte)), "a\xff\xfe", WithInvalidUTF8(InvalidUTF8Raw))
+	if err != nil || r != "a" {
+		t.Errorf("expected raw bytes to be distinguishable, got '%s' (%v)", r, err)
+	}
+}
+
+func TestRepeat(t *testing.T) {
+	letter := GetString(ConsumeIf(IsAsciiLetter))
+
+	expectResult := func(parser Parser[[]string], input string, expected string, remaining string) {
+		r, next, err := parser(State{Data: input, Offset: 0})
+		if err != nil {
+			t.Errorf("parser didn't parse '%s': %v", input, err)
+			return
+		}
+		if strings.Join(r, "") != expected || next.Remaining() != remaining {
+			t.Errorf("expected %s and remaining '%s', got %v and remaining '%s'", expected, remaining, r, next.Remaining())
+		}
+	}
+	expectFailure := func(parser Parser[[]string], input string) {
+		_, next, err := parser(State{Data: input, Offset: 0})
+		if err == nil {
+			t.Errorf("parser parsed '%s'", input)
+		}
+		if next.Offset != 0 {
+			t.Errorf("parser consumed although it failed")
+		}
+	}
+
+	expectResult(Many(letter), "abc1", "abc", "1")
+	expectResult(Many(letter), "1", "", "1")
+	expectResult(Many1(letter), "ab1", "ab", "1")
+	expectFailure(Many1(letter), "1")
+	expectResult(Count(letter, 2), "abc", "ab", "c")
+	expectFailure(Count(letter, 2), "a1")
+	expectResult(AtLeast(letter, 2), "abc", "abc", "")
+	expectFailure(AtLeast(letter, 2), "a")
+	expectResult(AtMost(letter, 2), "abc", "ab", "c")
+	expectResult(Repeat(letter, 1, 2), "a1", "a", "1")
+
+	r, err := Parse(Count(Succeed("x"), 3), "")
+	if err != nil || len(r) != 3 {
+		t.Errorf("expected 3 results of a non-consuming parser, got %v (%v)", r, err)
+	}
+	r, err = Parse(Many(Succeed("x")), "")
+	if err != nil || len(r) != 1 {
+		t.Errorf("expected Many to stop on a non-consuming parser, got %v (%v)", r, err)
+	}
+}
+
+func TestRepeat_fatal(t *testing.T) {
+	pair := AppendSkipping(StartSkipping(Exactly("a")), Cut(Exactly("b")))
+
+	_, err := Parse(Many(pair), "abac")
+	if !IsFatal(err) {
+		t.Errorf("expected fatal error, got %v", err)
+	}
+}
+
+func TestOptional(t *testing.T) {
+	sign := Optional(GetString(Exactly("-")))
+	parser := AppendKeeping(sign, GetString(ConsumeSome(IsDecimalDigit)))
+
+	r, err := Parse(parser, "-12")
+	if err != nil || r.A == nil || *r.A != "-" || r.B != "12" {
+		t.Errorf("expected -12, got %v (%v)", r, err)
+	}
+
+	r, err = Parse(parser, "12")
+	if err != nil || r.A != nil || r.B != "12" {
+		t.Errorf("expected 12 without sign, got %v (%v)", r, err)
+	}
+
+	_, err = Parse(Optional(Cut(Exactly("a"))), "b")
+	if !IsFatal(err) {
+		t.Errorf("expected fatal error, got %v", err)
+	}
+}
+
+func TestLookahead(t *testing.T) {
+	identifierChar := ConsumeIf(MatchAny(IsAsciiLetter, IsDecimalDigit))
+	keyword := NotFollowedBy(Exactly("if"), identifierChar)
+
+	_, _, err := keyword(State{Data: "if(", Offset: 0})
+	if err != nil {
+		t.Errorf("keyword didn't match: %v", err)
+	}
+
+	_, err = Parse(keyword, "iffy")
+	if !errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected ErrNoMatch, got %v", err)
+	}
+	if err == nil || err.Error() != `unexpected "f" at 1:3` {
+		t.Errorf("expected unexpected input error, got %v", err)
+	}
+
+	call := FollowedBy(GetString(ConsumeSome(IsAsciiLetter)), Exactly("("))
+	name, next, err := call(State{Data: "print(", Offset: 0})
+	if err != nil || name != "print" || next.Remaining() != "(" {
+		t.Errorf("expected print followed by (, got '%s' and '%s' (%v)", name, next.Remaining(), err)
+	}
+	_, err = Parse(call, "print[")
+	if err == nil || err.Error() != `expected "(" at 1:6` {
+		t.Errorf("expected error expecting (, got %v", err)
+	}
+
+	peeked, next, err := Peek(GetString(Exactly("ab")))(State{Data: "abc", Offset: 0})
+	if err != nil || peeked != "ab" || next.Offset != 0 {
+		t.Errorf("expected Peek to return ab without consuming, got '%s' at offset %d (%v)", peeked, next.Offset, err)
+	}
+
+	_, _, err = Not(Exactly("a"))(State{Data: "b", Offset: 0})
+	if err != nil {
+		t.Errorf("expected Not to succeed, got %v", err)
+	}
+}
+
+func TestEOF(t *testing.T) {
+	parser := AppendSkipping(StartSkipping(Exactly("a")), EOF)
+
+	_, _, err := parser(State{Data: "a", Offset: 0})
+	if err != nil {
+		t.Errorf("parser didn't parse: %v", err)
+	}
+
+	_, _, err = parser(State{Data: "ab", Offset: 0})
+	if err == nil || err.Error() != "expected end of input at 1:2" {
+		t.Errorf("expected error expecting end of input, got %v", err)
+	}
+}
+
+func TestManyTill(t *testing.T) {
+	letter := GetString(ConsumeIf(IsAsciiLetter))
+	parser := ManyTill(letter, Exactly("."))
+
+	r, next, err := parser(State{Data: "abc.d", Offset: 0})
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if strings.Join(r, "") != "abc" || next.Remaining() != "d" {
+		t.Errorf("expected abc and remaining 'd', got %v and remaining '%s'", r, next.Remaining())
+	}
+
+	r, _, err = parser(State{Data: ".", Offset: 0})
+	if err != nil || len(r) != 0 {
+		t.Errorf("expected no results, got %v (%v)", r, err)
+	}
+
+	_, err = Parse(parser, "ab1.")
+	if err == nil || err.Error() != `expected "." at 1:3` {
+		t.Errorf("expected error expecting '.', got %v", err)
+	}
+}
+
+func TestTakeUntil(t *testing.T) {
+	comment := Unpack(AppendKeeping(StartSkipping(Exactly("/*")), TakeUntil(Exactly("*/"))))
+
+	r, next, err := comment(State{Data: "/* a * b */ c", Offset: 0})
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if r != " a * b " || next.Remaining() != "*/ c" {
+		t.Errorf("expected ' a * b ' and remaining '*/ c', got '%s' and remaining '%s'", r, next.Remaining())
+	}
+
+	r, next, err = TakeThrough(Exactly("*/"))(State{Data: "a */ c", Offset: 0})
+	if err != nil || r != "a */" || next.Remaining() != " c" {
+		t.Errorf("expected 'a */' and remaining ' c', got '%s' and remaining '%s' (%v)", r, next.Remaining(), err)
+	}
+
+	_, next, err = SkipUntil(Exactly(";"))(State{Data: "x = 1; y", Offset: 0})
+	if err != nil || next.Remaining() != "; y" {
+		t.Errorf("expected remaining '; y', got '%s' (%v)", next.Remaining(), err)
+	}
+
+	long := "/*" + strings.Repeat("x", 200) + "*/"
+	if _, err := Parse(AppendSkipping(comment, Exactly("*/")), long, WithMaxBacktracks(100)); err != nil {
+		t.Errorf("expected TakeUntil not to count backtracks, got %v", err)
+	}
+	if _, err := Parse(ManyTill(ConsumeIf(IsAsciiLetter), Exactly(".")), strings.Repeat("x", 200)+".", WithMaxBacktracks(100)); err != nil {
+		t.Errorf("expected ManyTill not to count backtracks, got %v", err)
+	}
+
+	_, err = Parse(comment, "/* open")
+	if err == nil || err.Error() != `expected "*/" at 1:8` {
+		t.Errorf("expected error expecting '*/', got %v", err)
+	}
+}
@@ -0,0 +1,269 @@
+package paco
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// contextCheckInterval is the number of steps between two checks of the context
+	contextCheckInterval = 256
+	// defaultMaxDepth is the maximal nesting depth of rules if not configured otherwise
+	defaultMaxDepth = 1000
+	// defaultTabWidth is the distance between tab stops used for column computation if not configured otherwise
+	defaultTabWidth = 1
+)
+
+// InvalidUTF8Mode selects how rune based parsers like ConsumeIf handle invalid UTF-8, see WithInvalidUTF8
+type InvalidUTF8Mode int
+
+const (
+	// InvalidUTF8Replace passes each invalid byte to predicates as utf8.RuneError
+	InvalidUTF8Replace InvalidUTF8Mode = iota
+	// InvalidUTF8Reject fails fatally with ErrInvalidUTF8 at the first invalid byte
+	InvalidUTF8Reject
+	// InvalidUTF8Raw passes each invalid byte to predicates as a rune that RawByte converts back to the byte
+	InvalidUTF8Raw
+)
+
+// environment holds the settings and counters of a single parse run. It is shared by all states of the run.
+type environment struct {
+	ctx                context.Context
+	maxSteps           int
+	maxBacktracks      int
+	maxDepth           int
+	invalidUTF8        InvalidUTF8Mode
+	trailingWhitespace bool
+	tabWidth           int
+	trace              io.Writer
+	traceDepth         int
+	labelMode          LabelMode
+	warnings           *[]Warning
+	steps              int
+	backtracks         int
+	// err is set once the run has been aborted
+	err error
+}
+
+// Option configures a parse run
+type Option func(e *environment)
+
+// WithContext aborts the parse run once ctx is done, failing with the error of ctx at the position reached. It works
+// with all entry points, e.g. to cancel reading a slow stream with ParseReader.
+func WithContext(ctx context.Context) Option {
+	return func(e *environment) {
+		e.ctx = ctx
+	}
+}
+
+// WithMaxSteps limits the number of primitive parser invocations of a parse run. Parsing fails with
+// ErrBudgetExceeded once the limit is exceeded. 0 means no limit, which is the default.
+func WithMaxSteps(n int) Option {
+	return func(e *environment) {
+		e.maxSteps = n
+	}
+}
+
+// WithMaxBacktracks limits the number of alternatives OneOf may try after a failing one during a parse run.
+// Parsing fails with ErrBudgetExceeded once the limit is exceeded. 0 means no limit, which is the default.
+func WithMaxBacktracks(n int) Option {
+	return func(e *environment) {
+		e.maxBacktracks = n
+	}
+}
+
+// WithMaxDepth limits the nesting depth of rules, see Rule. The default is 1000, 0 means no limit.
+func WithMaxDepth(n int) Option {
+	return func(e *environment) {
+		e.maxDepth = n
+	}
+}
+
+// WithInvalidUTF8 selects how rune based parsers handle invalid UTF-8. The default is InvalidUTF8Replace.
+func WithInvalidUTF8(mode InvalidUTF8Mode) Option {
+	return func(e *environment) {
+		e.invalidUTF8 = mode
+	}
+}
+
+// WithTrailingWhitespace allows spaces, tabs and line breaks after the input consumed by the parser
+func WithTrailingWhitespace() Option {
+	return func(e *environment) {
+		e.trailingWhitespace = true
+	}
+}
+
+// WithTabWidth sets the distance between tab stops used for column computation. The default is 1, which 0 selects
+// as well.
+func WithTabWidth(n int) Option {
+	return func(e *environment) {
+		e.tabWidth = n
+	}
+}
+
+// WithTrace writes a trace of all labeled parsers and rules entered and left to w
+func WithTrace(w io.Writer) Option {
+	return func(e *environment) {
+		e.trace = w
+	}
+}
+
+// WithLabelMode selects which labels the messages of returned errors contain. The default is LabelsAll.
+func WithLabelMode(mode LabelMode) Option {
+	return func(e *environment) {
+		e.labelMode = mode
+	}
+}
+
+// WithWarningsTo appends the warnings recorded along the successful path to dst, see Warn. It works with all entry
+// points, e.g. to get the warnings of included files when parsing with ParseFile.
+func WithWarningsTo(dst *[]Warning) Option {
+	return func(e *environment) {
+		e.warnings = dst
+	}
+}
+
+func newEnvironment(opts []Option) *environment {
+	e := &environment{maxDepth: defaultMaxDepth, tabWidth: defaultTabWidth}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// step counts a parser invocation and returns a fatal error if the run has to be aborted
+func (s State) step() error {
+	e := s.env
+	if e == nil {
+		return s.abortError()
+	}
+	if e.err == nil {
+		e.steps++
+		if e.maxSteps > 0 && e.steps > e.maxSteps {
+			e.err = ErrBudgetExceeded
+		} else if e.ctx != nil && e.steps%contextCheckInterval == 1 {
+			e.err = e.ctx.Err()
+		}
+	}
+	return s.abortError()
+}
+
+// backtrack counts a backtrack and returns a fatal error if the run has to be aborted
+func (s State) backtrack() error {
+	e := s.env
+	if e == nil {
+		return s.abortError()
+	}
+	if e.err == nil {
+		e.backtracks++
+		if e.maxBacktracks > 0 && e.backtracks > e.maxBacktracks {
+			e.err = ErrBudgetExceeded
+		}
+	}
+	return s.abortError()
+}
+
+// maxDepth returns the maximal nesting depth of rules
+func (s State) maxDepth() int {
+	if s.env == nil {
+		return defaultMaxDepth
+	}
+	return s.env.maxDepth
+}
+
+// invalidUTF8 returns how invalid UTF-8 is handled
+func (s State) invalidUTF8() InvalidUTF8Mode {
+	if s.env == nil {
+		return InvalidUTF8Replace
+	}
+	return s.env.invalidUTF8
+}
+
+// tabWidth returns the distance between tab stops used for column computation
+func (s State) tabWidth() int {
+	if s.env == nil || s.env.tabWidth < 1 {
+		return defaultTabWidth
+	}
+	return s.env.tabWidth
+}
+
+// traceEnter writes the start of a labeled parser to the trace
+func (s State) traceEnter(label string) {
+	if s.env == nil || s.env.trace == nil {
+		return
+	}
+	fmt.Fprintf(s.env.trace, "%s%s at %s\n", strings.Repeat("  ", s.env.traceDepth), label, s.Position())
+	s.env.traceDepth++
+}
+
+// traceLeave writes the result of a labeled parser to the trace
+func (s State) traceLeave(label string, next State, err error) {
+	if s.env == nil || s.env.trace == nil {
+		return
+	}
+	s.env.traceDepth--
+	indent := strings.Repeat("  ", s.env.traceDepth)
+	if err != nil {
+		fmt.Fprintf(s.env.trace, "%s%s failed: %v\n", indent, label, err)
+		return
+	}
+	fmt.Fprintf(s.env.trace, "%s%s matched until %s\n", indent, label, next.Position())
+}
+
+// collectWarnings appends the warnings recorded on the way to s to the destination set with WithWarningsTo
+func (e *environment) collectWarnings(s State) {
+	if e != nil && e.warnings != nil {
+		*e.warnings = append(*e.warnings, s.warnings.values()...)
+	}
+}
+
+// finishError applies the error settings to an error returned from a parse run
+func (e *environment) finishError(err *ParseError) *ParseError {
+	if e == nil || e.labelMode == err.labelMode {
+		return err
+	}
+	finished := *err
+	finished.labelMode = e.labelMode
+	return &finished
+}
+
+// finishFailure applies the error settings to the error a parse run failed with. If errors were recovered from on
+// the way to it, they are returned together with it as ErrorList.
+func (e *environment) finishFailure(err *ParseError) error {
+	recovered := err.diagnostics.values()
+	if len(recovered) == 0 {
+		return e.finishError(err)
+	}
+	return e.finishErrors(append(recovered, err))
+}
+
+// finishErrors applies the error settings to all errors returned from a parse run
+func (e *environment) finishErrors(errs ErrorList) ErrorList {
+	finished := make(ErrorList, len(errs))
+	for i, err := range errs {
+		finished[i] = e.finishError(err)
+	}
+	return finished
+}
+
+// aborted returns true if the run has been aborted, either by the limits of the run or by an input error (see
+// ParseReader). No combinator may recover from errors of an aborted run.
+func (s State) aborted() bool {
+	return s.abortCause() != nil
+}
+
+func (s State) abortCause() error {
+	if s.env != nil && s.env.err != nil {
+		return s.env.err
+	}
+	return s.inputError()
+}
+
+func (s State) abortError() error {
+	if cause := s.abortCause(); cause != nil {
+		return newError(s, cause).fatal()
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package paco
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseContext_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ParseContext(ctx, Exactly("a"), "a")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWithContext_entry_points(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := ParseBytes(Exactly("a"), []byte("a"), WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled from ParseBytes, got %v", err)
+	}
+	if _, err := ParseReader(Exactly("a"), strings.NewReader("a"), WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled from ParseReader, got %v", err)
+	}
+	if _, err := ParseFile(Exactly("a"), NewFileSet().AddFile("a.txt", "a"), WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled from ParseFile, got %v", err)
+	}
+}
+
+func TestParseContext_max_steps(t *testing.T) {
+	letter := OneOf(Exactly("a"), Exactly("b"), Exactly("c"))
+	parser := Recover(RepeatWhile(letter, func(Empty) bool { return true }), Exactly(";"), nil)
+
+	_, err := ParseContext(context.Background(), parser, "abcabc", WithMaxSteps(100))
+	if err != nil {
+		t.Errorf("parser didn't parse: %v", err)
+	}
+
+	_, err = ParseContext(context.Background(), parser, "abcabc", WithMaxSteps(5))
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected ParseError, got %v", err)
+	}
+	if pe.Offset != 2 {
+		t.Errorf("expected budget to be exceeded at offset 2, got %d", pe.Offset)
+	}
+}
+
+func TestParseContext_max_backtracks(t *testing.T) {
+	letter := OneOf(Exactly("a"), Exactly("b"), Exactly("c"))
+	parser := RepeatWhile(letter, func(Empty) bool { return true })
+
+	_, err := ParseContext(context.Background(), parser, "ccc", WithMaxBacktracks(5))
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("expected ErrBudgetExceeded, got %v", err)
+	}
+}
+
+func TestParsePrefix(t *testing.T) {
+	r, rest, err := ParsePrefix(GetString(ConsumeSome(IsDecimalDigit)), "123abc")
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if r != "123" || rest.Remaining() != "abc" {
+		t.Errorf("expected 123 and remaining 'abc', got %s and '%s'", r, rest.Remaining())
+	}
+}
+
+func TestParse_trailing_whitespace(t *testing.T) {
+	_, err := Parse(Exactly("a"), "a \r\n\t")
+	if !errors.Is(err, ErrUnconsumedInput) {
+		t.Errorf("expected ErrUnconsumedInput, got %v", err)
+	}
+
+	_, err = Parse(Exactly("a"), "a \r\n\t", WithTrailingWhitespace())
+	if err != nil {
+		t.Errorf("parser didn't parse: %v", err)
+	}
+}
+
+func TestParse_tab_width(t *testing.T) {
+	parser := AppendSkipping(StartSkipping(Exactly("\t\ta")), Exactly("b"))
+
+	_, err := Parse(parser, "\t\tax", WithTabWidth(4))
+	if err == nil || err.Error() != `expected "b" at 1:10` {
+		t.Errorf("expected error at 1:10, got %v", err)
+	}
+}
+
+func TestParse_trace(t *testing.T) {
+	trace := strings.Builder{}
+	parser := WithLabel(AppendSkipping(WithLabel(Exactly("a"), "a"), WithLabel(Exactly("b"), "b")), "ab")
+
+	_, _ = Parse(parser, "ac", WithTrace(&trace))
+	expected := `ab at 1:1
+  a at 1:1
+  a matched until 1:2
+  b at 1:2
+  b failed: expected "b" at 1:2
+ab failed: error parsing b: expected b at 1:2
+`
+	if trace.String() != expected {
+		t.Errorf("expected trace\n%s\ngot\n%s", expected, trace.String())
+	}
+}
+
+func TestParse_label_mode(t *testing.T) {
+	parser := WithLabel(WithLabel(Exactly("a"), "inner"), "outer")
+
+	_, err := Parse(parser, "b", WithLabelMode(LabelsInnermost))
+	if err == nil || err.Error() != "error parsing inner: expected outer at 1:1" {
+		t.Errorf("expected only the innermost label, got %v", err)
+	}
+}
+
+func TestOptions_entry_points(t *testing.T) {
+	letters := ConsumeWhile(IsAsciiLetter)
+
+	_, err := ParseReader(letters, strings.NewReader("abc"), WithMaxSteps(0))
+	if err != nil {
+		t.Errorf("parser didn't parse: %v", err)
+	}
+	_, err = ParseReader(Many(ConsumeIf(IsAsciiLetter)), strings.NewReader("abcdef"), WithMaxSteps(3))
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("expected ErrBudgetExceeded when streaming, got %v", err)
+	}
+
+	_, err = ParseBytes(letters, []byte("ab\xff"), WithInvalidUTF8(InvalidUTF8Reject))
+	if !errors.Is(err, ErrInvalidUTF8) {
+		t.Errorf("expected ErrInvalidUTF8 for byte input, got %v", err)
+	}
+
+	parser := AppendSkipping(StartSkipping(Exactly("\t")), WithWarning(Exactly("a"), "deprecated"))
+	_, warnings, err := ParseWithWarnings(parser, "\ta", WithTabWidth(4))
+	if err != nil || len(warnings) != 1 || warnings[0].Column != 5 {
+		t.Errorf("expected warning at column 5, got %v (%v)", warnings, err)
+	}
+}
+
+func TestFileSet_Position_tab_width(t *testing.T) {
+	fset := NewFileSet()
+	file := fset.AddFile("tabs", "\tb")
+
+	_, err := ParseFile(AppendSkipping(StartSkipping(Exactly("\t")), Exactly("a")), file, WithTabWidth(8))
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected ParseError, got %v", err)
+	}
+	if pe.Column != 9 {
+		t.Errorf("expected error at column 9, got %d", pe.Column)
+	}
+	if actual := fset.Position(pe.Pos, WithTabWidth(8)); actual.Column != pe.Column {
+		t.Errorf("expected column %d, got %d", pe.Column, actual.Column)
+	}
+}
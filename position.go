@@ -0,0 +1,74 @@
+package paco
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Position describes a location in the input
+type Position struct {
+	// Filename is the name of the file when parsing a File of a FileSet
+	Filename string
+	// Offset is the byte offset
+	Offset int
+	// Line is the 1-based line. "\n", "\r\n" and "\r" each end a line.
+	Line int
+	// Column is the 1-based column in runes. Tabs advance to the next tab stop.
+	Column int
+}
+
+var startPosition = Position{Offset: 0, Line: 1, Column: 1}
+
+func (p Position) String() string {
+	if p.Filename != "" {
+		return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// advance returns the position after consuming chunk, which must start at p. afterCR tells whether the byte
+// before p is a carriage return, in which case a leading line feed doesn't start another line.
+func advance[S string | []byte](p Position, chunk S, afterCR bool, tabWidth int) Position {
+	for i := 0; i < len(chunk); i++ {
+		b := chunk[i]
+		switch {
+		case b == '\n':
+			if !afterCR {
+				p.Line++
+			}
+			p.Column = 1
+		case b == '\r':
+			p.Line++
+			p.Column = 1
+		case b == '\t':
+			p.Column = ((p.Column-1)/tabWidth+1)*tabWidth + 1
+		case !utf8.RuneStart(b):
+			// continuation bytes belong to the column of their rune
+		default:
+			p.Column++
+		}
+		afterCR = b == '\r'
+	}
+	p.Offset += len(chunk)
+	return p
+}
+
+// splitLines splits data into lines the same way positions count them
+func splitLines(data string) []string {
+	var lines []string
+	start := 0
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '\r':
+			lines = append(lines, data[start:i])
+			if i+1 < len(data) && data[i+1] == '\n' {
+				i++
+			}
+			start = i + 1
+		case '\n':
+			lines = append(lines, data[start:i])
+			start = i + 1
+		}
+	}
+	return append(lines, data[start:])
+}
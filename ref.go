@@ -0,0 +1,36 @@
+package paco
+
+import "fmt"
+
+// Ref is a forward-declared parser for recursive and mutually recursive grammars. Use Parser to refer to it in
+// other rules before its definition is given with Set. The zero value is a usable, unnamed reference.
+type Ref[T any] struct {
+	name   string
+	parser Parser[T]
+}
+
+// NewRef creates a reference to a rule with the given name. The name is used in error messages.
+func NewRef[T any](name string) *Ref[T] {
+	return &Ref[T]{name: name}
+}
+
+// Set defines the parser the reference refers to. It must be called before parsing starts.
+func (r *Ref[T]) Set(parser Parser[T]) {
+	r.parser = parser
+}
+
+// Parser returns a parser applying the referenced parser. Entering it counts as nesting a rule (see Rule).
+// Parsing fails fatally with ErrUnsetRef if Set hasn't been called.
+func (r *Ref[T]) Parser() Parser[T] {
+	name := r.name
+	if name == "" {
+		name = "ref"
+	}
+	return Rule(name, func(initial State) (T, State, error) {
+		if r.parser == nil {
+			var zero T
+			return zero, initial, newError(initial, fmt.Errorf("%w: rule %s", ErrUnsetRef, name)).fatal()
+		}
+		return r.parser(initial)
+	})
+}
@@ -0,0 +1,67 @@
+package paco
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRef(t *testing.T) {
+	list := NewRef[int]("list")
+	item := OneOf(list.Parser(), Map(ConsumeSome(IsDecimalDigit), func(Empty) int { return 1 }))
+	list.Set(Map(Between(Exactly("("), SepBy(item, Exactly(" ")), Exactly(")")), func(items []int) int {
+		sum := 0
+		for _, i := range items {
+			sum += i
+		}
+		return sum
+	}))
+
+	count, err := Parse(list.Parser(), "(1 (2 3) ((4)) 5)")
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if count != 5 {
+		t.Errorf("expected 5 numbers, got %d", count)
+	}
+
+	_, err = Parse(list.Parser(), strings.Repeat("(", 2000)+strings.Repeat(")", 2000))
+	if !errors.Is(err, ErrTooDeep) {
+		t.Errorf("expected ErrTooDeep, got %v", err)
+	}
+}
+
+func TestRef_unset(t *testing.T) {
+	var ref Ref[Empty]
+
+	_, err := Parse(OneOf(ref.Parser(), Exactly("a")), "a")
+	if !errors.Is(err, ErrUnsetRef) {
+		t.Errorf("expected ErrUnsetRef, got %v", err)
+	}
+}
+
+func TestLazy(t *testing.T) {
+	var value Parser[int]
+	array := Map(Between(Exactly("["), LazyNamed("array", func() Parser[int] { return value }), Exactly("]")), func(depth int) int {
+		return depth + 1
+	})
+	value = OneOf(array, Succeed(0))
+
+	depth, err := Parse(value, "[[[]]]")
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if depth != 3 {
+		t.Errorf("expected depth 3, got %d", depth)
+	}
+
+	_, err = Parse(value, strings.Repeat("[", 2000)+strings.Repeat("]", 2000))
+	if err == nil || !strings.Contains(err.Error(), "rule array is nested deeper") {
+		t.Errorf("expected error naming the rule, got %v", err)
+	}
+
+	_, err = Parse(Lazy(func() Parser[int] { return nil }), "")
+	if !errors.Is(err, ErrUnsetRef) {
+		t.Errorf("expected ErrUnsetRef, got %v", err)
+	}
+}
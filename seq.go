@@ -0,0 +1,247 @@
+package paco
+
+// Preceded runs skip and parser in order and returns the result of parser. Use it to attach ignored parts like
+// delimiters to a part of a sequence, so they don't appear in the result of SeqN.
+func Preceded[T, U any](skip Parser[U], parser Parser[T]) Parser[T] {
+	return func(initial State) (T, State, error) {
+		_, next, err := skip(initial)
+		if err != nil {
+			var zero T
+			return zero, initial, err
+		}
+		t, next, err := parser(next)
+		if err != nil {
+			return t, initial, err
+		}
+		return t, next, nil
+	}
+}
+
+// Terminated runs parser and skip in order and returns the result of parser, see Preceded
+func Terminated[T, U any](parser Parser[T], skip Parser[U]) Parser[T] {
+	return AppendSkipping(parser, skip)
+}
+
+// Seq2 runs 2 parsers in order and returns their results as a Tuple. It fails if any of them fails.
+func Seq2[A, B any](pa Parser[A], pb Parser[B]) Parser[Tuple[A, B]] {
+	return func(initial State) (Tuple[A, B], State, error) {
+		var t Tuple[A, B]
+		var err error
+		current := initial
+		if t.A, current, err = pa(current); err != nil {
+			return Tuple[A, B]{}, initial, err
+		}
+		if t.B, current, err = pb(current); err != nil {
+			return Tuple[A, B]{}, initial, err
+		}
+		return t, current, nil
+	}
+}
+
+// Seq3 runs 3 parsers in order and returns their results as a Tuple3. It fails if any of them fails.
+func Seq3[A, B, C any](pa Parser[A], pb Parser[B], pc Parser[C]) Parser[Tuple3[A, B, C]] {
+	return func(initial State) (Tuple3[A, B, C], State, error) {
+		var t Tuple3[A, B, C]
+		var err error
+		current := initial
+		if t.A, current, err = pa(current); err != nil {
+			return Tuple3[A, B, C]{}, initial, err
+		}
+		if t.B, current, err = pb(current); err != nil {
+			return Tuple3[A, B, C]{}, initial, err
+		}
+		if t.C, current, err = pc(current); err != nil {
+			return Tuple3[A, B, C]{}, initial, err
+		}
+		return t, current, nil
+	}
+}
+
+// Seq4 runs 4 parsers in order and returns their results as a Tuple4. It fails if any of them fails.
+func Seq4[A, B, C, D any](pa Parser[A], pb Parser[B], pc Parser[C], pd Parser[D]) Parser[Tuple4[A, B, C, D]] {
+	return func(initial State) (Tuple4[A, B, C, D], State, error) {
+		var t Tuple4[A, B, C, D]
+		var err error
+		current := initial
+		if t.A, current, err = pa(current); err != nil {
+			return Tuple4[A, B, C, D]{}, initial, err
+		}
+		if t.B, current, err = pb(current); err != nil {
+			return Tuple4[A, B, C, D]{}, initial, err
+		}
+		if t.C, current, err = pc(current); err != nil {
+			return Tuple4[A, B, C, D]{}, initial, err
+		}
+		if t.D, current, err = pd(current); err != nil {
+			return Tuple4[A, B, C, D]{}, initial, err
+		}
+		return t, current, nil
+	}
+}
+
+// Seq5 runs 5 parsers in order and returns their results as a Tuple5. It fails if any of them fails.
+func Seq5[A, B, C, D, E any](pa Parser[A], pb Parser[B], pc Parser[C], pd Parser[D], pe Parser[E]) Parser[Tuple5[A, B, C, D, E]] {
+	return func(initial State) (Tuple5[A, B, C, D, E], State, error) {
+		var t Tuple5[A, B, C, D, E]
+		var err error
+		current := initial
+		if t.A, current, err = pa(current); err != nil {
+			return Tuple5[A, B, C, D, E]{}, initial, err
+		}
+		if t.B, current, err = pb(current); err != nil {
+			return Tuple5[A, B, C, D, E]{}, initial, err
+		}
+		if t.C, current, err = pc(current); err != nil {
+			return Tuple5[A, B, C, D, E]{}, initial, err
+		}
+		if t.D, current, err = pd(current); err != nil {
+			return Tuple5[A, B, C, D, E]{}, initial, err
+		}
+		if t.E, current, err = pe(current); err != nil {
+			return Tuple5[A, B, C, D, E]{}, initial, err
+		}
+		return t, current, nil
+	}
+}
+
+// Seq6 runs 6 parsers in order and returns their results as a Tuple6. It fails if any of them fails.
+func Seq6[A, B, C, D, E, F any](pa Parser[A], pb Parser[B], pc Parser[C], pd Parser[D], pe Parser[E], pf Parser[F]) Parser[Tuple6[A, B, C, D, E, F]] {
+	return func(initial State) (Tuple6[A, B, C, D, E, F], State, error) {
+		var t Tuple6[A, B, C, D, E, F]
+		var err error
+		current := initial
+		if t.A, current, err = pa(current); err != nil {
+			return Tuple6[A, B, C, D, E, F]{}, initial, err
+		}
+		if t.B, current, err = pb(current); err != nil {
+			return Tuple6[A, B, C, D, E, F]{}, initial, err
+		}
+		if t.C, current, err = pc(current); err != nil {
+			return Tuple6[A, B, C, D, E, F]{}, initial, err
+		}
+		if t.D, current, err = pd(current); err != nil {
+			return Tuple6[A, B, C, D, E, F]{}, initial, err
+		}
+		if t.E, current, err = pe(current); err != nil {
+			return Tuple6[A, B, C, D, E, F]{}, initial, err
+		}
+		if t.F, current, err = pf(current); err != nil {
+			return Tuple6[A, B, C, D, E, F]{}, initial, err
+		}
+		return t, current, nil
+	}
+}
+
+// Seq7 runs 7 parsers in order and returns their results as a Tuple7. It fails if any of them fails.
+func Seq7[A, B, C, D, E, F, G any](pa Parser[A], pb Parser[B], pc Parser[C], pd Parser[D], pe Parser[E], pf Parser[F], pg Parser[G]) Parser[Tuple7[A, B, C, D, E, F, G]] {
+	return func(initial State) (Tuple7[A, B, C, D, E, F, G], State, error) {
+		var t Tuple7[A, B, C, D, E, F, G]
+		var err error
+		current := initial
+		if t.A, current, err = pa(current); err != nil {
+			return Tuple7[A, B, C, D, E, F, G]{}, initial, err
+		}
+		if t.B, current, err = pb(current); err != nil {
+			return Tuple7[A, B, C, D, E, F, G]{}, initial, err
+		}
+		if t.C, current, err = pc(current); err != nil {
+			return Tuple7[A, B, C, D, E, F, G]{}, initial, err
+		}
+		if t.D, current, err = pd(current); err != nil {
+			return Tuple7[A, B, C, D, E, F, G]{}, initial, err
+		}
+		if t.E, current, err = pe(current); err != nil {
+			return Tuple7[A, B, C, D, E, F, G]{}, initial, err
+		}
+		if t.F, current, err = pf(current); err != nil {
+			return Tuple7[A, B, C, D, E, F, G]{}, initial, err
+		}
+		if t.G, current, err = pg(current); err != nil {
+			return Tuple7[A, B, C, D, E, F, G]{}, initial, err
+		}
+		return t, current, nil
+	}
+}
+
+// Seq8 runs 8 parsers in order and returns their results as a Tuple8. It fails if any of them fails.
+func Seq8[A, B, C, D, E, F, G, H any](pa Parser[A], pb Parser[B], pc Parser[C], pd Parser[D], pe Parser[E], pf Parser[F], pg Parser[G], ph Parser[H]) Parser[Tuple8[A, B, C, D, E, F, G, H]] {
+	return func(initial State) (Tuple8[A, B, C, D, E, F, G, H], State, error) {
+		var t Tuple8[A, B, C, D, E, F, G, H]
+		var err error
+		current := initial
+		if t.A, current, err = pa(current); err != nil {
+			return Tuple8[A, B, C, D, E, F, G, H]{}, initial, err
+		}
+		if t.B, current, err = pb(current); err != nil {
+			return Tuple8[A, B, C, D, E, F, G, H]{}, initial, err
+		}
+		if t.C, current, err = pc(current); err != nil {
+			return Tuple8[A, B, C, D, E, F, G, H]{}, initial, err
+		}
+		if t.D, current, err = pd(current); err != nil {
+			return Tuple8[A, B, C, D, E, F, G, H]{}, initial, err
+		}
+		if t.E, current, err = pe(current); err != nil {
+			return Tuple8[A, B, C, D, E, F, G, H]{}, initial, err
+		}
+		if t.F, current, err = pf(current); err != nil {
+			return Tuple8[A, B, C, D, E, F, G, H]{}, initial, err
+		}
+		if t.G, current, err = pg(current); err != nil {
+			return Tuple8[A, B, C, D, E, F, G, H]{}, initial, err
+		}
+		if t.H, current, err = ph(current); err != nil {
+			return Tuple8[A, B, C, D, E, F, G, H]{}, initial, err
+		}
+		return t, current, nil
+	}
+}
+
+// Seq2Map runs 2 parsers in order and passes their results to mapper
+func Seq2Map[A, B, R any](pa Parser[A], pb Parser[B], mapper func(A, B) R) Parser[R] {
+	return Map(Seq2(pa, pb), func(t Tuple[A, B]) R {
+		return mapper(t.A, t.B)
+	})
+}
+
+// Seq3Map runs 3 parsers in order and passes their results to mapper
+func Seq3Map[A, B, C, R any](pa Parser[A], pb Parser[B], pc Parser[C], mapper func(A, B, C) R) Parser[R] {
+	return Map(Seq3(pa, pb, pc), func(t Tuple3[A, B, C]) R {
+		return mapper(t.A, t.B, t.C)
+	})
+}
+
+// Seq4Map runs 4 parsers in order and passes their results to mapper
+func Seq4Map[A, B, C, D, R any](pa Parser[A], pb Parser[B], pc Parser[C], pd Parser[D], mapper func(A, B, C, D) R) Parser[R] {
+	return Map(Seq4(pa, pb, pc, pd), func(t Tuple4[A, B, C, D]) R {
+		return mapper(t.A, t.B, t.C, t.D)
+	})
+}
+
+// Seq5Map runs 5 parsers in order and passes their results to mapper
+func Seq5Map[A, B, C, D, E, R any](pa Parser[A], pb Parser[B], pc Parser[C], pd Parser[D], pe Parser[E], mapper func(A, B, C, D, E) R) Parser[R] {
+	return Map(Seq5(pa, pb, pc, pd, pe), func(t Tuple5[A, B, C, D, E]) R {
+		return mapper(t.A, t.B, t.C, t.D, t.E)
+	})
+}
+
+// Seq6Map runs 6 parsers in order and passes their results to mapper
+func Seq6Map[A, B, C, D, E, F, R any](pa Parser[A], pb Parser[B], pc Parser[C], pd Parser[D], pe Parser[E], pf Parser[F], mapper func(A, B, C, D, E, F) R) Parser[R] {
+	return Map(Seq6(pa, pb, pc, pd, pe, pf), func(t Tuple6[A, B, C, D, E, F]) R {
+		return mapper(t.A, t.B, t.C, t.D, t.E, t.F)
+	})
+}
+
+// Seq7Map runs 7 parsers in order and passes their results to mapper
+func Seq7Map[A, B, C, D, E, F, G, R any](pa Parser[A], pb Parser[B], pc Parser[C], pd Parser[D], pe Parser[E], pf Parser[F], pg Parser[G], mapper func(A, B, C, D, E, F, G) R) Parser[R] {
+	return Map(Seq7(pa, pb, pc, pd, pe, pf, pg), func(t Tuple7[A, B, C, D, E, F, G]) R {
+		return mapper(t.A, t.B, t.C, t.D, t.E, t.F, t.G)
+	})
+}
+
+// Seq8Map runs 8 parsers in order and passes their results to mapper
+func Seq8Map[A, B, C, D, E, F, G, H, R any](pa Parser[A], pb Parser[B], pc Parser[C], pd Parser[D], pe Parser[E], pf Parser[F], pg Parser[G], ph Parser[H], mapper func(A, B, C, D, E, F, G, H) R) Parser[R] {
+	return Map(Seq8(pa, pb, pc, pd, pe, pf, pg, ph), func(t Tuple8[A, B, C, D, E, F, G, H]) R {
+		return mapper(t.A, t.B, t.C, t.D, t.E, t.F, t.G, t.H)
+	})
+}
@@ -0,0 +1,82 @@
+package paco
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSeq(t *testing.T) {
+	word := GetString(ConsumeSome(IsAsciiLetter))
+	number := Map(GetString(ConsumeSome(IsDecimalDigit)), func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return n
+	})
+
+	r, next, err := Seq2(word, Preceded(Exactly(" "), number))(State{Data: "age 42!", Offset: 0})
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if r.A != "age" || r.B != 42 || next.Remaining() != "!" {
+		t.Errorf("expected age and 42 with remaining '!', got %+v with remaining '%s'", r, next.Remaining())
+	}
+
+	_, next, err = Seq2(word, Preceded(Exactly(" "), number))(State{Data: "age x", Offset: 0})
+	if err == nil {
+		t.Errorf("parser parsed erroneously")
+	}
+	if next.Offset != 0 {
+		t.Errorf("parser consumed although it failed")
+	}
+
+	type entry struct {
+		key   string
+		value int
+	}
+	parser := Seq2Map(
+		Preceded(Exactly("("), Terminated(word, Exactly("="))),
+		Terminated(number, Exactly(")")),
+		func(key string, value int) entry {
+			return entry{key: key, value: value}
+		},
+	)
+	e, err := Parse(parser, "(a=1)")
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if e.key != "a" || e.value != 1 {
+		t.Errorf("expected a=1, got %+v", e)
+	}
+}
+
+func TestSeq8(t *testing.T) {
+	digit := func(d int) Parser[int] {
+		return MapEmpty(Exactly(strconv.Itoa(d)), d)
+	}
+	letter := func(l string) Parser[string] {
+		return GetString(Exactly(l))
+	}
+	parser := Seq8(digit(1), letter("b"), digit(3), letter("d"), digit(5), letter("f"), digit(7), letter("h"))
+
+	r, err := Parse(parser, "1b3d5f7h")
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	expected := Tuple8[int, string, int, string, int, string, int, string]{1, "b", 3, "d", 5, "f", 7, "h"}
+	if r != expected {
+		t.Errorf("expected %+v, got %+v", expected, r)
+	}
+
+	_, err = Parse(parser, "1b3d5f7x")
+	if err == nil || err.Error() != `expected "h" at 1:8` {
+		t.Errorf("expected error expecting h, got %v", err)
+	}
+
+	mapped := Seq8Map(digit(1), letter("b"), digit(3), letter("d"), digit(5), letter("f"), digit(7), letter("h"),
+		func(a int, b string, c int, d string, e int, f string, g int, h string) string {
+			return strconv.Itoa(a) + b + strconv.Itoa(c) + d + strconv.Itoa(e) + f + strconv.Itoa(g) + h
+		})
+	s, err := Parse(mapped, "1b3d5f7h")
+	if err != nil || s != "1b3d5f7h" {
+		t.Errorf("expected '1b3d5f7h', got '%s' (%v)", s, err)
+	}
+}
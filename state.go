@@ -1,29 +1,228 @@
 package paco
 
-import "unicode/utf8"
+import (
+	"strings"
+	"unicode/utf8"
+	"unsafe"
+)
 
 type State struct {
 	Data   string
 	Offset int
+	// pos is the position of Offset. It's only valid if pos.Offset equals Offset.
+	pos Position
+	// diagnostics contains the errors recorded by Recover
+	diagnostics *stack[*ParseError]
+	// warnings contains the warnings recorded by Warn and WithWarning
+	warnings *stack[Warning]
+	// failure is the furthest error a combinator recovered from on the way to this state
+	failure *ParseError
+	// user contains the values of user states by key, see UserState. It's copied on write.
+	user map[*int]any
+	// bytes is the input Data is backed by when parsing with ParseBytes
+	bytes []byte
+	// src provides the input instead of Data when parsing with ParseReader
+	src *source
+	// env holds the settings and counters of the parse run
+	env *environment
+	// depth is the number of enclosing rules
+	depth int
+	// file is the input Data belongs to when parsing with ParseFile
+	file *File
+}
+
+// stack is an immutable list of values recorded during parsing, most recent first. States share it, so
+// backtracking to an earlier state also discards values recorded after it.
+type stack[T any] struct {
+	value T
+	prev  *stack[T]
+}
+
+func (s *stack[T]) push(value T) *stack[T] {
+	return &stack[T]{value: value, prev: s}
+}
+
+// values returns all values in the order they were recorded
+func (s *stack[T]) values() []T {
+	var values []T
+	for current := s; current != nil; current = current.prev {
+		values = append(values, current.value)
+	}
+	for i, j := 0, len(values)-1; i < j; i, j = i+1, j-1 {
+		values[i], values[j] = values[j], values[i]
+	}
+	return values
+}
+
+// bytesState returns a state over data without copying it. Data aliases data, so data must not be modified while
+// the state is in use. Strings handed out to callers, e.g. by GetString and Remaining, are copies.
+func bytesState(data []byte) State {
+	return State{
+		Data:   unsafe.String(unsafe.SliceData(data), len(data)),
+		Offset: 0,
+		bytes:  data,
+	}
 }
 
 // HasRemaining returns true if the state has data left
 func (s State) HasRemaining() bool {
+	if s.src != nil {
+		return len(s.src.peek(s.Offset, 1)) > 0
+	}
 	return len(s.Data) > s.Offset
 }
 
-// Remaining returns the remaining data
+// Remaining returns the remaining data. When parsing with ParseReader, it returns only the currently buffered data.
 func (s State) Remaining() string {
+	if s.src != nil {
+		return string(s.src.buffered(s.Offset))
+	}
+	if s.bytes != nil {
+		return string(s.bytes[s.Offset:])
+	}
 	return s.Data[s.Offset:]
 }
 
-// Consume returns a new state with the offset advanced
+// Consume returns a new state with the offset advanced by n bytes, or to the end of the input if fewer remain
 func (s State) Consume(n int) State {
+	afterCR := s.Offset > 0 && s.byteAt(s.Offset-1) == '\r'
+	if s.src != nil {
+		chunk := s.src.peek(s.Offset, n)
+		s.pos = advance(s.Position(), chunk, afterCR, s.tabWidth())
+		s.Offset += len(chunk)
+		return s
+	}
+	if remaining := len(s.Data) - s.Offset; n > remaining {
+		n = remaining
+	}
+	s.pos = advance(s.Position(), s.Data[s.Offset:s.Offset+n], afterCR, s.tabWidth())
 	s.Offset += n
 	return s
 }
 
+// Position returns the position of the current offset
+func (s State) Position() Position {
+	pos := s.pos
+	if pos.Offset != s.Offset || pos.Line == 0 {
+		pos = advance(startPosition, s.Data[:s.Offset], false, s.tabWidth())
+	}
+	if s.file != nil {
+		pos.Filename = s.file.name
+	}
+	return pos
+}
+
+// NextRune decodes the next rune and returns it together with the state after it. At the end of the input it
+// returns utf8.RuneError without consuming. An invalid UTF-8 byte is consumed and returned as utf8.RuneError, or
+// as raw byte when parsing with InvalidUTF8Raw (see RawByte).
 func (s State) NextRune() (rune, State) {
-	r, w := utf8.DecodeRuneInString(s.Remaining())
+	r, w := s.decodeRune()
+	if w == 1 && r == utf8.RuneError && s.invalidUTF8() == InvalidUTF8Raw {
+		r = rawByteBase + rune(s.byteAt(s.Offset))
+	}
 	return r, s.Consume(w)
 }
+
+// nextRune works like NextRune but fails with a fatal ErrInvalidUTF8 error on invalid UTF-8 when parsing with
+// InvalidUTF8Reject
+func (s State) nextRune() (rune, State, error) {
+	if s.invalidUTF8() == InvalidUTF8Reject {
+		if r, w := s.decodeRune(); w == 1 && r == utf8.RuneError {
+			return r, s, newError(s, ErrInvalidUTF8).fatal()
+		}
+	}
+	r, next := s.NextRune()
+	return r, next, nil
+}
+
+func (s State) decodeRune() (rune, int) {
+	if s.src != nil {
+		return utf8.DecodeRune(s.src.peek(s.Offset, utf8.UTFMax))
+	}
+	return utf8.DecodeRuneInString(s.Data[s.Offset:])
+}
+
+func (s State) byteAt(offset int) byte {
+	if s.src != nil {
+		return s.src.byteAt(offset)
+	}
+	return s.Data[offset]
+}
+
+// hasPrefix returns true if the remaining data starts with token
+func (s State) hasPrefix(token string) bool {
+	if s.src != nil {
+		return string(s.src.peek(s.Offset, len(token))) == token
+	}
+	return strings.HasPrefix(s.Data[s.Offset:], token)
+}
+
+// slice returns the data between the given offsets. When parsing with ParseBytes, the result is a copy, so it
+// doesn't change when the input is modified.
+func (s State) slice(start, end int) (string, error) {
+	if s.src != nil {
+		b, err := s.src.slice(start, end)
+		return string(b), err
+	}
+	if s.bytes != nil {
+		return string(s.bytes[start:end]), nil
+	}
+	return s.Data[start:end], nil
+}
+
+// sliceBytes returns the data between the given offsets. The result is a sub-slice of the input if parsing with
+// ParseBytes and a copy otherwise.
+func (s State) sliceBytes(start, end int) ([]byte, error) {
+	if s.src != nil {
+		b, err := s.src.slice(start, end)
+		return append([]byte(nil), b...), err
+	}
+	if s.bytes != nil {
+		return s.bytes[start:end:end], nil
+	}
+	return []byte(s.Data[start:end]), nil
+}
+
+// withDiagnostic returns a new state with the given error recorded
+func (s State) withDiagnostic(err *ParseError) State {
+	s.diagnostics = s.diagnostics.push(err)
+	return s
+}
+
+// relabel returns the errors with the given frame added to all errors recorded after base, see WithLabel
+func relabel(errs, base *stack[*ParseError], frame LabelFrame) *stack[*ParseError] {
+	var recorded []*ParseError
+	for current := errs; current != base && current != nil; current = current.prev {
+		recorded = append(recorded, current.value)
+	}
+	if len(recorded) == 0 {
+		return errs
+	}
+	relabeled := base
+	for i := len(recorded) - 1; i >= 0; i-- {
+		relabeled = relabeled.push(recorded[i].withLabel(frame))
+	}
+	return relabeled
+}
+
+// withWarning returns a new state with the given warning recorded
+func (s State) withWarning(w Warning) State {
+	s.warnings = s.warnings.push(w)
+	return s
+}
+
+// withFailure returns a new state remembering the given error if it got further than the one remembered already
+func (s State) withFailure(err *ParseError) State {
+	s.failure = furthest(s.failure, err)
+	return s
+}
+
+// errors returns all recorded errors in the order they were recorded
+func (s State) errors() ErrorList {
+	return s.diagnostics.values()
+}
+
+// GetPosition returns the current position without consuming input
+func GetPosition(initial State) (Position, State, error) {
+	return initial.Position(), initial, nil
+}
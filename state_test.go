@@ -2,6 +2,7 @@ package paco
 
 import (
 	"testing"
+	"unicode/utf8"
 )
 
 func TestState_Consume(t *testing.T) {
@@ -18,6 +19,9 @@ func TestState_Consume(t *testing.T) {
 	if s3.HasRemaining() {
 		t.Errorf("state has remaining, expected false")
 	}
+
+	s4 := s2.Consume(5)
+	expectState(t, s4, "", 3)
 }
 
 func expectState(t *testing.T, state State, remaining string, offset int) {
@@ -28,3 +32,61 @@ func expectState(t *testing.T, state State, remaining string, offset int) {
 		t.Errorf("expected offset %d, got %d", offset, state.Offset)
 	}
 }
+
+func TestState_Position(t *testing.T) {
+	data := "ab\ncd\r\nef\rg\th"
+	expectPosition := func(offset, line, column int) {
+		for _, s := range []State{
+			{Data: data, Offset: offset},
+			State{Data: data, Offset: 0}.Consume(offset),
+			State{Data: data, Offset: 0}.Consume(offset / 2).Consume(offset - offset/2),
+		} {
+			pos := s.Position()
+			if pos.Offset != offset || pos.Line != line || pos.Column != column {
+				t.Errorf("expected %d:%d at offset %d, got %d:%d at offset %d", line, column, offset, pos.Line, pos.Column, pos.Offset)
+			}
+		}
+	}
+
+	expectPosition(0, 1, 1)
+	expectPosition(2, 1, 3)
+	expectPosition(3, 2, 1)
+	expectPosition(5, 2, 3)
+	expectPosition(6, 3, 1)
+	expectPosition(7, 3, 1)
+	expectPosition(10, 4, 1)
+	expectPosition(12, 4, 3)
+	expectPosition(13, 4, 4)
+}
+
+func TestGetPosition(t *testing.T) {
+	parser := AppendKeeping(StartSkipping(Exactly("a\r\nb")), GetPosition)
+
+	r, err := Parse(parser, "a\r\nb")
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if r.B.String() != "2:2" {
+		t.Errorf("expected position 2:2, got %s", r.B)
+	}
+}
+
+func TestState_NextRune(t *testing.T) {
+	r, next := State{Data: "\xff", Offset: 0}.NextRune()
+	if r != utf8.RuneError || next.Offset != 1 {
+		t.Errorf("expected RuneError of width 1, got %q at offset %d", r, next.Offset)
+	}
+
+	r, next = State{Data: "", Offset: 0}.NextRune()
+	if r != utf8.RuneError || next.Offset != 0 {
+		t.Errorf("expected RuneError without consuming, got %q at offset %d", r, next.Offset)
+	}
+
+	b, ok := RawByte(0xDCFF)
+	if !ok || b != 0xFF {
+		t.Errorf("expected raw byte 0xFF, got %x", b)
+	}
+	if _, ok := RawByte('a'); ok {
+		t.Errorf("expected 'a' not to be a raw byte")
+	}
+}
@@ -0,0 +1,174 @@
+package paco
+
+import "io"
+
+const (
+	// readChunkSize is the minimal number of bytes requested from a reader at once
+	readChunkSize = 4096
+	// defaultMaxBuffer is the maximal number of bytes buffered at once; reading beyond it fails with ErrBufferFull
+	defaultMaxBuffer = 64 << 20
+)
+
+// source is a refillable buffer over a reader. It buffers the input from the lowest offset a state may still be
+// read at, see State.mark.
+type source struct {
+	r io.Reader
+	// buf contains the input starting at offset base
+	buf  []byte
+	base int
+	// err is the first error returned by r, io.EOF at the end of the input
+	err       error
+	maxBuffer int
+	// marks contains the offsets of the states combinators may continue from, see State.mark
+	marks []int
+}
+
+func readerState(r io.Reader, opts []Option) State {
+	return State{
+		Offset: 0,
+		pos:    startPosition,
+		src:    &source{r: r, maxBuffer: defaultMaxBuffer},
+		env:    newEnvironment(opts),
+	}
+}
+
+// fill reads from the reader until the input up to offset end is buffered or the input ends. offset is the offset
+// being read at, input before it and the lowest mark is released when the buffer is full.
+func (src *source) fill(offset, end int) {
+	for src.err == nil && src.base+len(src.buf) < end {
+		if len(src.buf) == cap(src.buf) {
+			src.makeRoom(offset)
+			if src.err != nil {
+				return
+			}
+		}
+		n, err := src.r.Read(src.buf[len(src.buf):cap(src.buf)])
+		src.buf = src.buf[:len(src.buf)+n]
+		if err != nil {
+			src.err = err
+		}
+	}
+}
+
+// makeRoom makes space for reading more input. It releases the input no state can be read at anymore and grows the
+// buffer unless that freed at least half of it, so compacting stays amortized.
+func (src *source) makeRoom(offset int) {
+	src.release(offset)
+	if len(src.buf) < cap(src.buf) && 2*len(src.buf) <= cap(src.buf) {
+		return
+	}
+	if cap(src.buf) >= src.maxBuffer {
+		if len(src.buf) == cap(src.buf) {
+			src.err = ErrBufferFull
+		}
+		return
+	}
+	size := 2*cap(src.buf) + readChunkSize
+	if size > src.maxBuffer {
+		size = src.maxBuffer
+	}
+	grown := make([]byte, len(src.buf), size)
+	copy(grown, src.buf)
+	src.buf = grown
+}
+
+// peek returns up to n bytes starting at offset, reading more input if needed. It returns fewer bytes at the end
+// of the input and none if offset has been released.
+func (src *source) peek(offset, n int) []byte {
+	if src.released(offset) {
+		return nil
+	}
+	src.fill(offset, offset+n)
+	start := offset - src.base
+	if start > len(src.buf) {
+		return nil
+	}
+	end := start + n
+	if end > len(src.buf) {
+		end = len(src.buf)
+	}
+	return src.buf[start:end]
+}
+
+// buffered returns the currently buffered input from offset on, reading more input if nothing is buffered
+func (src *source) buffered(offset int) []byte {
+	if src.released(offset) {
+		return nil
+	}
+	src.fill(offset, offset+1)
+	start := offset - src.base
+	if start > len(src.buf) {
+		return nil
+	}
+	return src.buf[start:]
+}
+
+// released returns true if the input at offset has been released. Reading released input aborts the parse run
+// with ErrReleasedInput, so it can't be mistaken for the end of the input.
+func (src *source) released(offset int) bool {
+	if offset >= src.base {
+		return false
+	}
+	if src.err == nil || src.err == io.EOF {
+		src.err = ErrReleasedInput
+	}
+	return true
+}
+
+// byteAt returns the byte at offset or 0 if it is not buffered
+func (src *source) byteAt(offset int) byte {
+	if b := src.peek(offset, 1); len(b) > 0 {
+		return b[0]
+	}
+	return 0
+}
+
+// slice returns the input between the given offsets. It fails if the input has been released already.
+func (src *source) slice(start, end int) ([]byte, error) {
+	if start < src.base {
+		return nil, ErrReleasedInput
+	}
+	return src.peek(start, end-start), nil
+}
+
+// release discards the buffered input before offset and the lowest mark. The byte right before is kept for
+// position tracking.
+func (src *source) release(offset int) {
+	for _, m := range src.marks {
+		if m < offset {
+			offset = m
+		}
+	}
+	drop := offset - 1 - src.base
+	if drop <= 0 {
+		return
+	}
+	n := copy(src.buf, src.buf[drop:])
+	src.buf = src.buf[:n]
+	src.base += drop
+}
+
+// mark keeps the input from the state's offset on buffered until unmark is called. When parsing with ParseReader,
+// input is released as soon as no state can be read at anymore. Combinators that continue from a state after
+// applying a parser to it, e.g. to try an alternative, must mark it while the parser runs. Calls to mark and
+// unmark must be nested.
+func (s State) mark() {
+	if s.src != nil {
+		s.src.marks = append(s.src.marks, s.Offset)
+	}
+}
+
+// unmark removes the mark added last, see mark
+func (s State) unmark() {
+	if s.src != nil {
+		s.src.marks = s.src.marks[:len(s.src.marks)-1]
+	}
+}
+
+// inputError returns the error the reader failed with, if any
+func (s State) inputError() error {
+	if s.src == nil || s.src.err == io.EOF {
+		return nil
+	}
+	return s.src.err
+}
@@ -0,0 +1,92 @@
+package paco
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestParseReader(t *testing.T) {
+	word := GetString(ConsumeSome(IsAsciiLetter))
+	parser := SepBy(word, Exactly("\r\n"))
+
+	words, err := ParseReader(parser, iotest.OneByteReader(strings.NewReader("hello\r\nstreaming\r\nworld")))
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if strings.Join(words, " ") != "hello streaming world" {
+		t.Errorf("expected [hello streaming world], got %v", words)
+	}
+
+	_, err = ParseReader(parser, iotest.OneByteReader(strings.NewReader("hello\r\nstreaming\r\n!")))
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected ParseError, got %v", err)
+	}
+	if pe.Line != 3 || pe.Column != 1 {
+		t.Errorf("expected error at 3:1, got %d:%d", pe.Line, pe.Column)
+	}
+}
+
+func TestParseReader_release(t *testing.T) {
+	line := AppendSkipping(StartSkipping(ConsumeWhile(IsNoneOf('\n'))), Exactly("\n"))
+	var maxBuffered int
+	measure := func(initial State) (Empty, State, error) {
+		if n := len(initial.src.buf); n > maxBuffered {
+			maxBuffered = n
+		}
+		return empty, initial, nil
+	}
+	parser := RepeatWhile(AppendSkipping(line, measure), func(Empty) bool { return true })
+
+	input := strings.Repeat(strings.Repeat("x", 100)+"\n", 1000)
+	_, err := ParseReader(parser, iotest.HalfReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if maxBuffered > 2*readChunkSize {
+		t.Errorf("expected buffer to stay small, got %d bytes", maxBuffered)
+	}
+}
+
+func TestParseReader_backtracking(t *testing.T) {
+	input := strings.Repeat("a", 3*readChunkSize)
+	letters := ConsumeSome(IsAsciiLetter)
+	parser := OneOf(AppendSkipping(letters, Exactly("X")), letters)
+
+	_, err := ParseReader(parser, iotest.HalfReader(strings.NewReader(input)))
+	if err != nil {
+		t.Errorf("expected backtracking to keep the input buffered, got %v", err)
+	}
+
+	r, err := ParseReader(Many(GetString(parser)), iotest.HalfReader(strings.NewReader(input)))
+	if err != nil || len(r) != 1 || len(r[0]) != len(input) {
+		t.Errorf("expected a single item with all input, got %d items (%v)", len(r), err)
+	}
+}
+
+func TestParseReader_released_input(t *testing.T) {
+	letters := ConsumeWhile(IsAsciiLetter)
+	// reads the input twice without marking the initial state
+	twice := func(initial State) (Empty, State, error) {
+		if _, _, err := AppendSkipping(letters, Exactly("X"))(initial); err == nil {
+			return empty, initial, nil
+		}
+		return letters(initial)
+	}
+
+	_, err := ParseReader(twice, strings.NewReader(strings.Repeat("a", 3*readChunkSize)))
+	if !errors.Is(err, ErrReleasedInput) {
+		t.Errorf("expected ErrReleasedInput, got %v", err)
+	}
+}
+
+func TestParseReader_reader_error(t *testing.T) {
+	readErr := errors.New("connection reset")
+
+	_, err := ParseReader(ConsumeWhile(IsAsciiLetter), iotest.ErrReader(readErr))
+	if !errors.Is(err, readErr) {
+		t.Errorf("expected reader error, got %v", err)
+	}
+}
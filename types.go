@@ -6,6 +6,12 @@ import (
 
 var ErrNoMatch = fmt.Errorf("no match")
 var ErrUnconsumedInput = fmt.Errorf("unconsumed input")
+var ErrBufferFull = fmt.Errorf("input buffer full")
+var ErrReleasedInput = fmt.Errorf("input has been released")
+var ErrBudgetExceeded = fmt.Errorf("parsing budget exceeded")
+var ErrTooDeep = fmt.Errorf("maximum nesting depth exceeded")
+var ErrInvalidUTF8 = fmt.Errorf("invalid UTF-8")
+var ErrUnsetRef = fmt.Errorf("reference is not set")
 
 type Empty struct{}
 
@@ -18,3 +24,60 @@ type Tuple[T, U any] struct {
 	A T
 	B U
 }
+
+// Tuple3 is a tuple of three values
+type Tuple3[A, B, C any] struct {
+	A A
+	B B
+	C C
+}
+
+// Tuple4 is a tuple of four values
+type Tuple4[A, B, C, D any] struct {
+	A A
+	B B
+	C C
+	D D
+}
+
+// Tuple5 is a tuple of five values
+type Tuple5[A, B, C, D, E any] struct {
+	A A
+	B B
+	C C
+	D D
+	E E
+}
+
+// Tuple6 is a tuple of six values
+type Tuple6[A, B, C, D, E, F any] struct {
+	A A
+	B B
+	C C
+	D D
+	E E
+	F F
+}
+
+// Tuple7 is a tuple of seven values
+type Tuple7[A, B, C, D, E, F, G any] struct {
+	A A
+	B B
+	C C
+	D D
+	E E
+	F F
+	G G
+}
+
+// Tuple8 is a tuple of eight values
+type Tuple8[A, B, C, D, E, F, G, H any] struct {
+	A A
+	B B
+	C C
+	D D
+	E E
+	F F
+	G G
+	H H
+}
@@ -0,0 +1,53 @@
+package paco
+
+// UserState is a typed handle to a value carried along a parse run, e.g. a symbol table. The value is part of State,
+// so changes are rolled back automatically when a combinator backtracks to an earlier state. A grammar may use any
+// number of user states, each of them holds values of type U only. Create handles with NewUserState.
+type UserState[U any] struct {
+	key *int
+}
+
+// NewUserState creates a handle to a new user state. Its value is the zero value of U until it is set, see
+// ParseWithUserState to start with a different value.
+func NewUserState[U any]() UserState[U] {
+	return UserState[U]{key: new(int)}
+}
+
+// Value returns the value of the user state in the given state
+func (s UserState[U]) Value(state State) U {
+	u, _ := state.user[s.key].(U)
+	return u
+}
+
+// Get returns the value of the user state without consuming input
+func (s UserState[U]) Get() Parser[U] {
+	return func(initial State) (U, State, error) {
+		return s.Value(initial), initial, nil
+	}
+}
+
+// Set sets the value of the user state without consuming input
+func (s UserState[U]) Set(value U) Parser[Empty] {
+	return func(initial State) (Empty, State, error) {
+		return empty, s.with(initial, value), nil
+	}
+}
+
+// Modify replaces the value of the user state with the result of modify. Like Set, the modification is rolled back
+// on backtracking, so modify must not mutate the given value in place.
+func (s UserState[U]) Modify(modify func(U) U) Parser[Empty] {
+	return func(initial State) (Empty, State, error) {
+		return empty, s.with(initial, modify(s.Value(initial))), nil
+	}
+}
+
+// with returns a copy of state with the value of the user state replaced
+func (s UserState[U]) with(state State, value U) State {
+	user := make(map[*int]any, len(state.user)+1)
+	for k, v := range state.user {
+		user[k] = v
+	}
+	user[s.key] = value
+	state.user = user
+	return state
+}
@@ -0,0 +1,69 @@
+package paco
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUserState(t *testing.T) {
+	declared := NewUserState[[]string]()
+	declare := FlatMap(
+		Unpack(AppendKeeping(StartSkipping(Exactly("var ")), GetString(ConsumeSome(IsAsciiLetter)))),
+		func(name string) Parser[Empty] {
+			return declared.Modify(func(names []string) []string {
+				return append(names[:len(names):len(names)], name)
+			})
+		},
+	)
+	known := FlatMap(GetString(ConsumeSome(IsAsciiLetter)), func(name string) Parser[Empty] {
+		return FlatMap(declared.Get(), func(names []string) Parser[Empty] {
+			for _, d := range names {
+				if d == name {
+					return Succeed(empty)
+				}
+			}
+			return FailWith[Empty]("%s is not declared", name)
+		})
+	})
+	statement := OneOf(
+		AppendSkipping(declare, Exactly("!")),
+		declare,
+		known,
+	)
+	parser := SepBy(statement, Exactly(";"))
+
+	_, names, err := ParseWithUserState(parser, "var a;var b!;a;b", declared, []string{})
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if strings.Join(names, ",") != "a,b" {
+		t.Errorf("expected declared [a b], got %v", names)
+	}
+
+	_, _, err = ParseWithUserState(parser, "var a;b", declared, nil)
+	if err == nil {
+		t.Errorf("parser parsed undeclared variable")
+	}
+}
+
+func TestUserState_independent(t *testing.T) {
+	count := NewUserState[int]()
+	last := NewUserState[string]()
+	word := FlatMap(GetString(ConsumeSome(IsAsciiLetter)), func(w string) Parser[Empty] {
+		return AppendSkipping(count.Modify(func(n int) int { return n + 1 }), last.Set(w))
+	})
+	parser := AppendSkipping(StartSkipping(SepBy(word, Exactly(" "))), last.Get())
+
+	_, n, err := ParseWithUserState(parser, "a b c", count, 10)
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if n != 13 {
+		t.Errorf("expected count 13, got %d", n)
+	}
+
+	_, w, err := ParseWithUserState(parser, "a b c", last, "")
+	if err != nil || w != "c" {
+		t.Errorf("expected last word c, got '%s' (%v)", w, err)
+	}
+}
@@ -0,0 +1,52 @@
+package paco
+
+import "fmt"
+
+// Warning is a non-fatal diagnostic recorded during parsing. Warnings don't affect the parsing result, they are
+// returned by ParseWithWarnings or collected with WithWarningsTo.
+type Warning struct {
+	// Filename is the name of the file the warning refers to when parsing a File of a FileSet
+	Filename string
+	// Offset is the byte offset the warning refers to
+	Offset int
+	// Line is the 1-based line the warning refers to
+	Line int
+	// Column is the 1-based column the warning refers to
+	Column int
+	// Message describes the warning
+	Message string
+}
+
+func newWarning(s State, format string, args ...any) Warning {
+	pos := s.Position()
+	return Warning{
+		Filename: pos.Filename,
+		Offset:   pos.Offset,
+		Line:     pos.Line,
+		Column:   pos.Column,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s at %s", w.Message, Position{Filename: w.Filename, Offset: w.Offset, Line: w.Line, Column: w.Column})
+}
+
+// Warn records a warning at the current position without consuming input
+func Warn(format string, args ...any) Parser[Empty] {
+	return func(initial State) (Empty, State, error) {
+		return empty, initial.withWarning(newWarning(initial, format, args...)), nil
+	}
+}
+
+// WithWarning runs the given parser and, if it succeeds, records a warning at the start of the parsed input.
+// Use it to accept deprecated constructs.
+func WithWarning[T any](parser Parser[T], format string, args ...any) Parser[T] {
+	return func(initial State) (T, State, error) {
+		t, next, err := parser(initial)
+		if err != nil {
+			return t, initial, err
+		}
+		return t, next.withWarning(newWarning(initial, format, args...)), nil
+	}
+}
@@ -0,0 +1,62 @@
+package paco
+
+import "testing"
+
+func TestWarn(t *testing.T) {
+	legacy := WithWarning(Exactly("var"), "%s is deprecated", "var")
+	keyword := OneOf(
+		AppendSkipping(legacy, Exactly("!")),
+		legacy,
+		Exactly("let"),
+	)
+	parser := SepBy(keyword, Exactly(" "))
+
+	_, warnings, err := ParseWithWarnings(parser, "let var")
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %v", warnings)
+	}
+	if warnings[0].Offset != 4 || warnings[0].Line != 1 || warnings[0].Column != 5 {
+		t.Errorf("expected warning at offset 4 (1:5), got %d (%d:%d)", warnings[0].Offset, warnings[0].Line, warnings[0].Column)
+	}
+	expected := "var is deprecated at 1:5"
+	if warnings[0].String() != expected {
+		t.Errorf("expected '%s', got '%s'", expected, warnings[0].String())
+	}
+}
+
+func TestWarn_backtracking(t *testing.T) {
+	parser := OneOf(
+		AppendSkipping(Warn("first"), Exactly("a")),
+		AppendSkipping(Warn("second"), Exactly("b")),
+	)
+
+	_, warnings, err := ParseWithWarnings(parser, "b")
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Message != "second" {
+		t.Errorf("expected only warning 'second', got %v", warnings)
+	}
+}
+
+func TestWithWarningsTo_file(t *testing.T) {
+	fset := NewFileSet()
+	other := fset.AddFile("other.conf", "old")
+	main := fset.AddFile("main.conf", "include;")
+	parser := AppendSkipping(
+		AppendSkipping(StartSkipping(Exactly("include")), Include(other, WithWarning(Exactly("old"), "deprecated"))),
+		Exactly(";"),
+	)
+
+	var warnings []Warning
+	_, err := ParseFile(parser, main, WithWarningsTo(&warnings))
+	if err != nil {
+		t.Fatalf("parser didn't parse: %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].String() != "deprecated at other.conf:1:1" {
+		t.Errorf("expected warning in other.conf, got %v", warnings)
+	}
+}